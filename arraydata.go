@@ -0,0 +1,152 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"reflect"
+	"strings"
+)
+
+type ArrayComponent struct {
+	Name       string
+	Offset     int
+	Components int
+	Type       gl.Enum
+	Normalized bool
+}
+
+type ArrayLayout struct {
+	ElementSize int
+	Count       int
+	Components  []ArrayComponent
+}
+
+// ArrayData returns the backing memory of a slice of fixed size numbers,
+// arrays or structs without copying it. It panics for any other data.
+func ArrayData(data interface{}) (gl.Pointer, gl.Sizeiptr, ArrayLayout) {
+	v := reflect.ValueOf(data)
+	layout, err := LayoutOfSlice(reflect.TypeOf(data))
+	if err != nil {
+		panic(err)
+	}
+	layout.Count = v.Len()
+	if v.Len() == 0 {
+		return gl.Pointer(nil), 0, layout
+	}
+	return gl.Pointer(v.UnsafePointer()), gl.Sizeiptr(v.Len() * layout.ElementSize), layout
+}
+
+func LayoutOfSlice(t reflect.Type) (ArrayLayout, error) {
+	if t == nil || t.Kind() != reflect.Slice {
+		return ArrayLayout{}, fmt.Errorf("array data must be a slice, not %v", t)
+	}
+	layout, ok := LayoutOfElement(t.Elem())
+	if !ok {
+		return layout, fmt.Errorf("array data %v is not made of fixed size numbers; int and uint have no fixed size, use int32 or uint32", t)
+	}
+	return layout, nil
+}
+
+func LayoutOfElement(t reflect.Type) (ArrayLayout, bool) {
+	layout := ArrayLayout{ElementSize: int(t.Size())}
+	ok := appendComponents(&layout, t, "", 0, false)
+	return layout, ok
+}
+
+func componentType(k reflect.Kind) (gl.Enum, bool) {
+	switch k {
+	case reflect.Int8:
+		return gl.BYTE, true
+	case reflect.Uint8:
+		return gl.UNSIGNED_BYTE, true
+	case reflect.Int16:
+		return gl.SHORT, true
+	case reflect.Uint16:
+		return gl.UNSIGNED_SHORT, true
+	case reflect.Int32:
+		return gl.INT, true
+	case reflect.Uint32:
+		return gl.UNSIGNED_INT, true
+	case reflect.Float32:
+		return gl.FLOAT, true
+	case reflect.Float64:
+		return gl.DOUBLE, true
+	}
+	return 0, false
+}
+
+func appendComponents(layout *ArrayLayout, t reflect.Type, name string, offset int, normalized bool) bool {
+	if ct, ok := componentType(t.Kind()); ok {
+		layout.Components = append(layout.Components, ArrayComponent{name, offset, 1, ct, normalized})
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		if ct, ok := componentType(t.Elem().Kind()); ok {
+			layout.Components = append(layout.Components, ArrayComponent{name, offset, t.Len(), ct, normalized})
+			return true
+		}
+		elemSize := int(t.Elem().Size())
+		for i := 0; i < t.Len(); i++ {
+			if !appendComponents(layout, t.Elem(), fmt.Sprintf("%s[%d]", name, i), offset+i*elemSize, normalized) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldName, fieldNormalized := componentTag(field)
+			if name != "" {
+				fieldName = name + "." + fieldName
+			}
+			if !appendComponents(layout, field.Type, fieldName, offset+int(field.Offset), fieldNormalized) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// componentTag reads names like `gl:"color,normalized"`, defaulting to the
+// field name with a lower case first letter.
+func componentTag(field reflect.StructField) (string, bool) {
+	tag := strings.Split(field.Tag.Get("gl"), ",")
+	name := tag[0]
+	if name == "" {
+		name = strings.ToLower(field.Name[:1]) + field.Name[1:]
+	}
+	normalized := false
+	for _, option := range tag[1:] {
+		if option == "normalized" {
+			normalized = true
+		}
+	}
+	return name, normalized
+}
+
+func (layout ArrayLayout) VertexFormat() *VertexFormat {
+	format := &VertexFormat{make([]VertexAttribute, 0, len(layout.Components)), layout.ElementSize}
+	for _, c := range layout.Components {
+		integer := !c.Normalized && c.Type != gl.FLOAT && c.Type != gl.DOUBLE
+		format.Attributes = append(format.Attributes, VertexAttribute{c.Name, c.Components, c.Type, c.Normalized, integer, c.Offset})
+	}
+	return format
+}
+
+func VertexFormatOf(data interface{}) (*VertexFormat, error) {
+	layout, err := LayoutOfSlice(reflect.TypeOf(data))
+	if err != nil {
+		return nil, err
+	}
+	return layout.VertexFormat(), nil
+}
+
+func (geometry *Geometry) AddVertexData(data interface{}) (*VertexStream, error) {
+	format, err := VertexFormatOf(data)
+	if err != nil {
+		return nil, err
+	}
+	return geometry.AddStream(format, data), nil
+}
@@ -0,0 +1,122 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testVertex struct {
+	Position [3]float32
+	Color    [4]uint8 `gl:"color,normalized"`
+	Bone     uint16   `gl:"boneIndex"`
+}
+
+func TestArrayDataLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        interface{}
+		elementSize int
+		attributes  []VertexAttribute
+	}{
+		{"float32", []float32{1, 2}, 4, []VertexAttribute{{"", 1, gl.FLOAT, false, false, 0}}},
+		{"float64", []float64{1, 2}, 8, []VertexAttribute{{"", 1, gl.DOUBLE, false, false, 0}}},
+		{"uint16", []uint16{1}, 2, []VertexAttribute{{"", 1, gl.UNSIGNED_SHORT, false, true, 0}}},
+		{"vec3", [][3]float32{{1, 2, 3}}, 12, []VertexAttribute{{"", 3, gl.FLOAT, false, false, 0}}},
+		{"color", []Color{{1, 0, 0, 1}}, 16, []VertexAttribute{{"", 4, gl.FLOAT, false, false, 0}}},
+		{"struct", []testVertex{{}}, 20, []VertexAttribute{
+			{"position", 3, gl.FLOAT, false, false, 0},
+			{"color", 4, gl.UNSIGNED_BYTE, true, false, 12},
+			{"boneIndex", 1, gl.UNSIGNED_SHORT, false, true, 16},
+		}},
+	}
+	for _, test := range tests {
+		format, err := VertexFormatOf(test.data)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if format.Stride != test.elementSize || !reflect.DeepEqual(format.Attributes, test.attributes) {
+			t.Errorf("%s: format %+v, want stride %d attributes %+v", test.name, format, test.elementSize, test.attributes)
+		}
+	}
+}
+
+func TestArrayDataRejectsUnsized(t *testing.T) {
+	for _, data := range []interface{}{[]int{1}, []uint{1}, []string{"a"}, 3, nil} {
+		_, err := VertexFormatOf(data)
+		if err == nil {
+			t.Errorf("%T: no error", data)
+		}
+	}
+	_, err := VertexFormatOf([]int{1})
+	if err == nil || !strings.Contains(err.Error(), "int32") {
+		t.Errorf("[]int: error %v should suggest a sized type", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("ArrayData([]int) did not panic")
+		}
+	}()
+	ArrayData([]int{1, 2})
+}
+
+func TestArrayPtrConverts(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		want interface{}
+	}{
+		{"float64 as floats", []float64{1.5, -2}, []float32{1.5, -2}},
+		{"int as unsigned shorts", []int{1, 65535}, []uint16{1, 65535}},
+		{"float32 as is", []float32{3}, []float32{3}},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		previous := SetBackend(r)
+		BindArrayData(1, test.data)
+		SetBackend(previous)
+		var want bytes.Buffer
+		binary.Write(&want, binary.LittleEndian, test.want)
+		if got := r.BufferContents[1]; !bytes.Equal(got, want.Bytes()) {
+			t.Errorf("%s: uploaded % x, want % x", test.name, got, want.Bytes())
+		}
+	}
+}
+
+func TestAddVertexDataUploadsMatchingLayout(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	data := []float64{1.5, -2.25, 3}
+	geometry := &Geometry{}
+	stream, err := geometry.AddVertexData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	binary.Write(&want, binary.LittleEndian, data)
+	if got := r.BufferContents[stream.Buffer]; !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("uploaded % x, want % x", got, want.Bytes())
+	}
+	a := stream.Format.Attributes[0]
+	if a.Type != gl.DOUBLE || stream.Format.Stride != 8 {
+		t.Errorf("format %+v does not describe doubles", stream.Format)
+	}
+}
+
+func TestNewGeometryUploadsFloats(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	geometry := NewGeometry("triangle", []float64{0, 0, 0, 1, 0, 0, 0, 1, 0}, []float64{0, 0, 1, 0, 0, 1, 0, 0, 1}, nil)
+	for _, stream := range geometry.Streams {
+		if got := len(r.BufferContents[stream.Buffer]); got != 9*4 {
+			t.Errorf("%s stream holds %d bytes, want %d", stream.Format.Attributes[0].Name, got, 9*4)
+		}
+		if stream.Format.Stride != 12 || stream.Format.Attributes[0].Type != gl.FLOAT {
+			t.Errorf("format %+v does not describe floats", stream.Format)
+		}
+	}
+}
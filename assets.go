@@ -0,0 +1,234 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type cachedAsset struct {
+	key     string
+	value   interface{}
+	refs    int
+	release func()
+}
+
+// AssetManager loads textures, programs and models by logical name, found
+// in FS when set and otherwise in the first search path containing them.
+// Each load adds a reference to the cached asset and each release removes
+// one, deleting the gl objects when the last reference goes. Programs share
+// their stage shaders, which are deleted with the last program using them.
+// Like the rest of the gl state it must only be used from the gl thread.
+type AssetManager struct {
+	FS          fs.FS
+	SearchPaths []string
+	Shaders     ShaderLibrary
+	byKey       map[string]*cachedAsset
+	byValue     map[interface{}]*cachedAsset
+	shaderRefs  map[string]int
+}
+
+func NewAssetManager(searchPaths ...string) *AssetManager {
+	m := &AssetManager{
+		SearchPaths: searchPaths,
+		Shaders:     NewShaderLibrary(),
+		byKey:       make(map[string]*cachedAsset),
+		byValue:     make(map[interface{}]*cachedAsset),
+		shaderRefs:  make(map[string]int),
+	}
+	m.Shaders.ReadFile = m.ReadFile
+	return m
+}
+
+func NewAssetManagerFS(fsys fs.FS) *AssetManager {
+	m := NewAssetManager()
+	m.FS = fsys
+	return m
+}
+
+// Resolve returns the path name refers to, relative to FS when it is set.
+func (m *AssetManager) Resolve(name string) (string, error) {
+	if m.FS != nil {
+		name = filepath.ToSlash(filepath.Clean(name))
+		_, err := fs.Stat(m.FS, name)
+		return name, err
+	}
+	if filepath.IsAbs(name) || len(m.SearchPaths) == 0 {
+		_, err := os.Stat(name)
+		return name, err
+	}
+	for _, dir := range m.SearchPaths {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", &os.PathError{Op: "resolve", Path: name, Err: os.ErrNotExist}
+}
+
+func (m *AssetManager) ReadFile(name string) ([]byte, error) {
+	path, err := m.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if m.FS != nil {
+		return fs.ReadFile(m.FS, path)
+	}
+	return ioutil.ReadFile(path)
+}
+
+func (m *AssetManager) acquire(key string, load func() (interface{}, func(), error)) (interface{}, error) {
+	if asset, ok := m.byKey[key]; ok {
+		asset.refs++
+		return asset.value, nil
+	}
+	value, release, err := load()
+	if err != nil {
+		return nil, err
+	}
+	asset := &cachedAsset{key, value, 1, release}
+	m.byKey[key] = asset
+	m.byValue[value] = asset
+	return value, nil
+}
+
+// Release drops a reference to a texture, program or model returned by
+// the manager and reports whether it was deleted.
+func (m *AssetManager) Release(value interface{}) bool {
+	asset, ok := m.byValue[value]
+	if !ok {
+		panic(fmt.Sprintln("asset not managed:", value))
+	}
+	asset.refs--
+	if asset.refs > 0 {
+		return false
+	}
+	asset.release()
+	delete(m.byKey, asset.key)
+	delete(m.byValue, value)
+	return true
+}
+
+func (m *AssetManager) Refs(value interface{}) int {
+	if asset, ok := m.byValue[value]; ok {
+		return asset.refs
+	}
+	return 0
+}
+
+func (m *AssetManager) Texture(name string, options TextureOptions) (gl.Texture, error) {
+	key := fmt.Sprintf("texture:%s:%+v", name, options)
+	value, err := m.acquire(key, func() (interface{}, func(), error) {
+		data, err := m.ReadFile(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		textureData, err := DecodeTextureData(data, options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+		texture := GenTexture()
+		err = textureData.Upload(texture, options)
+		if err != nil {
+			DeleteTexture(texture)
+			return nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+		return texture, func() { DeleteTexture(texture) }, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value.(gl.Texture), nil
+}
+
+func (m *AssetManager) Program(stages ProgramStages, options ShaderOptions) (gl.Program, error) {
+	shaders := shaderKeys(stages, options)
+	key := "program:" + programKey(shaders)
+	value, err := m.acquire(key, func() (interface{}, func(), error) {
+		for _, shader := range shaders {
+			m.shaderRefs[shader]++
+		}
+		err := m.Shaders.BuildProgramWithOptions(key, stages, options)
+		if err != nil {
+			m.releaseShaders(shaders)
+			return nil, nil, err
+		}
+		program, _ := m.Shaders.GetProgram(key)
+		return program, func() {
+			m.Shaders.DeleteProgram(key)
+			m.releaseShaders(shaders)
+		}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value.(gl.Program), nil
+}
+
+// releaseShaders drops a program's references to its stage shaders,
+// deleting those no other program uses.
+func (m *AssetManager) releaseShaders(keys []string) {
+	for _, key := range keys {
+		m.shaderRefs[key]--
+		if m.shaderRefs[key] > 0 {
+			continue
+		}
+		delete(m.shaderRefs, key)
+		if shader, ok := m.Shaders.Shaders[key]; ok {
+			DeleteShader(shader)
+			delete(m.Shaders.Shaders, key)
+		}
+	}
+}
+
+func shaderKeys(stages ProgramStages, options ShaderOptions) []string {
+	files := stages.files()
+	keys := make([]string, len(files))
+	for i, f := range files {
+		keys[i] = shaderKey(f.Stage, f.Filename, options)
+	}
+	return keys
+}
+
+func programKey(shaders []string) string {
+	key := ""
+	for _, shader := range shaders {
+		key += shader + "|"
+	}
+	return key
+}
+
+// Model returns the scene loaded from a Collada file. The model is shared
+// by every caller, so it should not be modified.
+func (m *AssetManager) Model(name string) (*Model, error) {
+	value, err := m.acquire("model:"+name, func() (interface{}, func(), error) {
+		data, err := m.ReadFile(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		index, err := DecodeScene(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+		model := NewSceneModel(index)
+		return model, model.Delete, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Model), nil
+}
+
+// Close deletes every cached asset regardless of references.
+func (m *AssetManager) Close() {
+	for value, asset := range m.byValue {
+		asset.release()
+		delete(m.byKey, asset.key)
+		delete(m.byValue, value)
+	}
+	m.Shaders.Delete()
+}
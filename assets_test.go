@@ -0,0 +1,170 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// liveCount counts the recorded objects of one kind still alive.
+func liveCount(r *RecordingBackend, kind ResourceKind) int {
+	n := 0
+	for resource := range r.Live {
+		if resource.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAssetManagerResolveFS(t *testing.T) {
+	m := NewAssetManagerFS(fstest.MapFS{"shaders/a.vert": {Data: []byte("void main() {}\n")}})
+	path, err := m.Resolve("shaders/./lib/../a.vert")
+	if err != nil || path != "shaders/a.vert" {
+		t.Errorf("resolved %q error %v", path, err)
+	}
+	if _, err := m.Resolve("shaders/b.vert"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file error %v", err)
+	}
+	data, err := m.ReadFile("shaders/../shaders/a.vert")
+	if err != nil || string(data) != "void main() {}\n" {
+		t.Errorf("read %q error %v", data, err)
+	}
+}
+
+func TestAssetManagerResolveSearchPaths(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	write := func(dir, name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	both := write(first, "both.png")
+	write(second, "both.png")
+	later := write(second, "later.png")
+	m := NewAssetManager(first, second)
+	tests := []struct {
+		name, want string
+	}{
+		{"both.png", both},
+		{"later.png", later},
+		{later, later},
+	}
+	for _, test := range tests {
+		path, err := m.Resolve(test.name)
+		if err != nil || path != test.want {
+			t.Errorf("%s: resolved %q error %v, want %q", test.name, path, err, test.want)
+		}
+	}
+	if _, err := m.Resolve("missing.png"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file error %v", err)
+	}
+}
+
+func TestAssetManagerTextureRefs(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, patterned(2, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	m := NewAssetManagerFS(fstest.MapFS{"a.png": {Data: encoded.Bytes()}})
+	texture, err := m.Texture("a.png", DefaultTextureOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := m.Texture("./a.png", DefaultTextureOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cache is keyed by the name asked for, so a differently spelled
+	// path loads its own copy.
+	if again == texture || m.Refs(texture) != 1 {
+		t.Errorf("texture %d and %d, %d refs", texture, again, m.Refs(texture))
+	}
+	m.Release(again)
+	shared, err := m.Texture("a.png", DefaultTextureOptions)
+	if err != nil || shared != texture || m.Refs(texture) != 2 {
+		t.Fatalf("texture %d error %v, %d refs", shared, err, m.Refs(texture))
+	}
+	if m.Release(texture) || m.Refs(texture) != 1 || liveCount(r, TextureResource) != 1 {
+		t.Errorf("first release: %d refs, live %v", m.Refs(texture), r.Live)
+	}
+	if !m.Release(texture) || m.Refs(texture) != 0 || len(r.Live) != 0 {
+		t.Errorf("last release: %d refs, live %v", m.Refs(texture), r.Live)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("released texture released again")
+		}
+	}()
+	m.Release(texture)
+}
+
+func TestAssetManagerProgramShaders(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	source := &fstest.MapFile{Data: []byte("void main() {}\n")}
+	m := NewAssetManagerFS(fstest.MapFS{"a.vert": source, "a.frag": source, "b.frag": source})
+	a, err := m.Program(ProgramStages{Vertex: "a.vert", Fragment: "a.frag"}, ShaderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.Program(ProgramStages{Vertex: "a.vert", Fragment: "b.frag"}, ShaderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := liveCount(r, ShaderResource); n != 3 {
+		t.Errorf("%d shaders compiled, want 3", n)
+	}
+	m.Release(a)
+	if liveCount(r, ProgramResource) != 1 || liveCount(r, ShaderResource) != 2 {
+		t.Errorf("after releasing a, live %v", r.Live)
+	}
+	m.Release(b)
+	if len(r.Live) != 0 || len(m.Shaders.Shaders) != 0 {
+		t.Errorf("after releasing b, live %v shaders %v", r.Live, m.Shaders.Shaders)
+	}
+
+	r.CompileStatus = false
+	if _, err := m.Program(ProgramStages{Vertex: "a.vert", Fragment: "a.frag"}, ShaderOptions{}); err == nil {
+		t.Fatal("broken program built")
+	}
+	if len(m.shaderRefs) != 0 || len(r.Live) != 0 {
+		t.Errorf("failed build left shader refs %v, live %v", m.shaderRefs, r.Live)
+	}
+}
+
+func TestAssetManagerClose(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, patterned(2, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	source := &fstest.MapFile{Data: []byte("void main() {}\n")}
+	m := NewAssetManagerFS(fstest.MapFS{"a.png": {Data: encoded.Bytes()}, "a.vert": source, "a.frag": source})
+	texture, err := m.Texture("a.png", DefaultTextureOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Texture("a.png", DefaultTextureOptions)
+	program, err := m.Program(ProgramStages{Vertex: "a.vert", Fragment: "a.frag"}, ShaderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	if len(r.Live) != 0 {
+		t.Errorf("leaked %v", r.Live)
+	}
+	if m.Refs(texture) != 0 || m.Refs(program) != 0 {
+		t.Errorf("refs %d %d after close", m.Refs(texture), m.Refs(program))
+	}
+}
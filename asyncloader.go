@@ -0,0 +1,229 @@
+package render
+
+import (
+	"fmt"
+	collada "github.com/GlenKelley/go-collada"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"sort"
+	"sync"
+	"time"
+)
+
+type AssetState int
+
+const (
+	AssetLoading AssetState = iota
+	AssetReady
+	AssetFailed
+)
+
+// asset tracks the progress of a handle. It is only updated from
+// AsyncLoader.Process, so handles must be read on the gl thread.
+type asset struct {
+	state AssetState
+	err   error
+}
+
+func (a *asset) State() AssetState {
+	return a.state
+}
+
+func (a *asset) Ready() bool {
+	return a.state == AssetReady
+}
+
+func (a *asset) Err() error {
+	return a.err
+}
+
+type TextureHandle struct {
+	asset
+	Filename string
+	Texture  gl.Texture
+}
+
+type ModelHandle struct {
+	asset
+	Filename string
+	Model    *Model
+}
+
+// An uploadStep runs on the gl thread and reports whether the upload is
+// complete; incomplete steps are called again, budget permitting.
+type uploadStep func() (bool, error)
+
+type uploadJob struct {
+	asset *asset
+	step  uploadStep
+}
+
+// AsyncLoader reads and decodes assets on worker goroutines and uploads
+// them from Process, which must be called on the gl thread each frame.
+type AsyncLoader struct {
+	Budget  time.Duration
+	workers chan struct{}
+	mutex   sync.Mutex // guards uploads and pending
+	uploads []uploadJob
+	pending int
+}
+
+func NewAsyncLoader(workers int, budget time.Duration) *AsyncLoader {
+	return &AsyncLoader{Budget: budget, workers: make(chan struct{}, workers)}
+}
+
+// Pending returns the number of loads that are not yet ready or failed.
+func (l *AsyncLoader) Pending() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.pending
+}
+
+func (l *AsyncLoader) finish() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.pending--
+}
+
+func (l *AsyncLoader) submit(a *asset, decode func() (uploadStep, error)) {
+	l.mutex.Lock()
+	l.pending++
+	l.mutex.Unlock()
+	go func() {
+		l.workers <- struct{}{}
+		step, err := decodeAsset(decode)
+		<-l.workers
+		if err != nil {
+			step = func() (bool, error) {
+				return true, err
+			}
+		}
+		l.mutex.Lock()
+		l.uploads = append(l.uploads, uploadJob{a, step})
+		l.mutex.Unlock()
+	}()
+}
+
+func decodeAsset(decode func() (uploadStep, error)) (step uploadStep, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return decode()
+}
+
+// withCleanup turns a panic in step into an error and runs cleanup when
+// step fails, so a failed upload leaves no gl objects behind.
+func withCleanup(step uploadStep, cleanup func()) uploadStep {
+	return func() (done bool, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				done, err = true, fmt.Errorf("%v", r)
+			}
+			if err != nil {
+				cleanup()
+			}
+		}()
+		return step()
+	}
+}
+
+func (l *AsyncLoader) next() (uploadJob, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if len(l.uploads) == 0 {
+		return uploadJob{}, false
+	}
+	job := l.uploads[0]
+	l.uploads = l.uploads[1:]
+	return job, true
+}
+
+func (l *AsyncLoader) requeue(job uploadJob) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.uploads = append([]uploadJob{job}, l.uploads...)
+}
+
+// Process runs queued uploads until the budget is spent. At least one step
+// runs per call so loading always makes progress.
+func (l *AsyncLoader) Process() {
+	start := time.Now()
+	for n := 0; n == 0 || time.Since(start) < l.Budget; n++ {
+		job, ok := l.next()
+		if !ok {
+			return
+		}
+		done, err := job.step()
+		switch {
+		case err != nil:
+			job.asset.state, job.asset.err = AssetFailed, err
+			l.finish()
+		case done:
+			job.asset.state = AssetReady
+			l.finish()
+		default:
+			l.requeue(job)
+		}
+	}
+}
+
+func (l *AsyncLoader) LoadTexture(filename string, options TextureOptions) *TextureHandle {
+	handle := &TextureHandle{Filename: filename}
+	l.submit(&handle.asset, func() (uploadStep, error) {
+		data, err := ReadTextureData(filename, options)
+		if err != nil {
+			return nil, err
+		}
+		var texture gl.Texture
+		return withCleanup(func() (bool, error) {
+			texture = GenTexture()
+			err := data.Upload(texture, options)
+			if err != nil {
+				return true, err
+			}
+			handle.Texture = texture
+			return true, nil
+		}, func() {
+			if texture != 0 {
+				DeleteTexture(texture)
+			}
+		}), nil
+	})
+	return handle
+}
+
+// LoadSceneAsModel uploads one mesh per step, then assembles the model. The
+// meshes uploaded so far are deleted if any step fails.
+func (l *AsyncLoader) LoadSceneAsModel(filename string) *ModelHandle {
+	handle := &ModelHandle{Filename: filename}
+	l.submit(&handle.asset, func() (uploadStep, error) {
+		index, err := ReadScene(filename)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]collada.Id, 0, len(index.Mesh))
+		for id := range index.Mesh {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		geometryTemplates := make(map[collada.Id][]*SharedGeometry)
+		return withCleanup(func() (bool, error) {
+			if len(ids) > 0 {
+				id := ids[0]
+				ids = ids[1:]
+				addMeshGeometry(id, index.Mesh[id], geometryTemplates)
+				return false, nil
+			}
+			handle.Model = AssembleSceneModel(index, geometryTemplates)
+			return true, nil
+		}, func() {
+			for _, geoms := range geometryTemplates {
+				for _, geom := range geoms {
+					geom.Geometry.Delete()
+				}
+			}
+		}), nil
+	})
+	return handle
+}
@@ -0,0 +1,137 @@
+package render
+
+import (
+	"errors"
+	collada "github.com/GlenKelley/go-collada"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithCleanup(t *testing.T) {
+	failure := errors.New("upload failed")
+	tests := []struct {
+		name    string
+		step    uploadStep
+		done    bool
+		err     string
+		cleaned bool
+	}{
+		{"done", func() (bool, error) { return true, nil }, true, "", false},
+		{"incomplete", func() (bool, error) { return false, nil }, false, "", false},
+		{"error", func() (bool, error) { return true, failure }, true, "upload failed", true},
+		{"panic", func() (bool, error) { panic("gl error 1282") }, true, "gl error 1282", true},
+	}
+	for _, test := range tests {
+		cleaned := false
+		done, err := withCleanup(test.step, func() { cleaned = true })()
+		if done != test.done || cleaned != test.cleaned {
+			t.Errorf("%s: done %v cleaned %v, want %v %v", test.name, done, cleaned, test.done, test.cleaned)
+		}
+		if (err == nil) != (test.err == "") || err != nil && !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestAsyncLoaderTextureFailure(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	// A DDS whose pixels end early decodes off the gl thread and fails
+	// there, so nothing is uploaded.
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "broken.dds")
+	data := ddsFile(ddsOptions{width: 2, height: 2, pixelFlags: ddsPixelFormatRGB, bits: 32, red: 0xff}, sequence(0, 8))
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	loader := NewAsyncLoader(2, time.Second)
+	handle := loader.LoadTexture(filename, DefaultTextureOptions)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Pending may be polled from any goroutine.
+		for loader.Pending() > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	for deadline := time.Now().Add(5 * time.Second); handle.State() == AssetLoading; {
+		if time.Now().After(deadline) {
+			t.Fatal("load never finished")
+		}
+		loader.Process()
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+	if handle.State() != AssetFailed || handle.Err() == nil || handle.Texture != 0 {
+		t.Errorf("handle %+v", handle)
+	}
+	if len(r.Live) != 0 {
+		t.Errorf("leaked %v", r.Live)
+	}
+}
+
+func TestAsyncLoaderDeletesPartialUpload(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	loader := NewAsyncLoader(1, time.Second)
+	handle := &ModelHandle{}
+	var uploaded []*Geometry
+	loader.submit(&handle.asset, func() (uploadStep, error) {
+		steps := 0
+		return withCleanup(func() (bool, error) {
+			steps++
+			if steps == 3 {
+				panic("out of memory")
+			}
+			uploaded = append(uploaded, NewGeometry("mesh", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+			return false, nil
+		}, func() {
+			for _, geometry := range uploaded {
+				geometry.Delete()
+			}
+		}), nil
+	})
+	for deadline := time.Now().Add(5 * time.Second); handle.State() == AssetLoading; {
+		if time.Now().After(deadline) {
+			t.Fatal("load never finished")
+		}
+		loader.Process()
+	}
+	if handle.State() != AssetFailed || loader.Pending() != 0 {
+		t.Errorf("state %v pending %d", handle.State(), loader.Pending())
+	}
+	if len(uploaded) != 2 || len(r.Live) != 0 {
+		t.Errorf("uploaded %d geometries, leaked %v", len(uploaded), r.Live)
+	}
+}
+
+func TestAddMeshGeometryPanicKeepsUploads(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	triangle := &Polylist{[]float64{0, 0, 0, 1, 0, 0, 0, 1, 0}, []float64{0, 0, 1, 0, 0, 1, 0, 0, 1}, []uint32{0, 1, 2}}
+	// The nil polylist panics after two geometries have been uploaded.
+	mesh := &Mesh{Polylist: []*Polylist{triangle, triangle, nil, triangle}}
+	geometryTemplates := make(map[collada.Id][]*SharedGeometry)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("nil polylist uploaded")
+			}
+		}()
+		addMeshGeometry("mesh", mesh, geometryTemplates)
+	}()
+	if n := len(geometryTemplates["mesh"]); n != 2 {
+		t.Fatalf("%d geometries recorded, want 2", n)
+	}
+	for _, shared := range geometryTemplates["mesh"] {
+		shared.Geometry.Delete()
+	}
+	if len(r.Live) != 0 {
+		t.Errorf("leaked %v", r.Live)
+	}
+}
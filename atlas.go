@@ -0,0 +1,266 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type AtlasOptions struct {
+	PageWidth  int
+	PageHeight int
+	Padding    int
+	Bleed      bool
+}
+
+// AtlasRegion locates a packed image in pixels and in texture coordinates.
+// V runs down from the top of the page, matching a page uploaded without
+// FlipY.
+type AtlasRegion struct {
+	Page   int     `json:"page"`
+	X      int     `json:"x"`
+	Y      int     `json:"y"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	U0     float64 `json:"u0"`
+	V0     float64 `json:"v0"`
+	U1     float64 `json:"u1"`
+	V1     float64 `json:"v1"`
+}
+
+type Atlas struct {
+	Width   int                    `json:"width"`
+	Height  int                    `json:"height"`
+	Pages   []string               `json:"pages"`
+	Regions map[string]AtlasRegion `json:"regions"`
+	Images  []*image.NRGBA         `json:"-"`
+	Dir     string                 `json:"-"`
+}
+
+type skylineNode struct {
+	X, Y, Width int
+}
+
+// skyline packs rectangles bottom-left along the top edge of everything
+// placed so far.
+type skyline struct {
+	Width, Height int
+	nodes         []skylineNode
+}
+
+func newSkyline(width, height int) *skyline {
+	return &skyline{width, height, []skylineNode{{0, 0, width}}}
+}
+
+func (s *skyline) fit(i, width, height int) (int, bool) {
+	if s.nodes[i].X+width > s.Width {
+		return 0, false
+	}
+	y := 0
+	for remaining := width; remaining > 0; i++ {
+		if s.nodes[i].Y > y {
+			y = s.nodes[i].Y
+		}
+		if y+height > s.Height {
+			return 0, false
+		}
+		remaining -= s.nodes[i].Width
+	}
+	return y, true
+}
+
+func (s *skyline) insert(width, height int) (image.Point, bool) {
+	best, bestY, bestWidth := -1, 0, 0
+	for i, node := range s.nodes {
+		y, ok := s.fit(i, width, height)
+		if ok && (best < 0 || y < bestY || y == bestY && node.Width < bestWidth) {
+			best, bestY, bestWidth = i, y, node.Width
+		}
+	}
+	if best < 0 {
+		return image.Point{}, false
+	}
+	p := image.Pt(s.nodes[best].X, bestY)
+	s.nodes = append(s.nodes[:best], append([]skylineNode{{p.X, p.Y + height, width}}, s.nodes[best:]...)...)
+	for i := best + 1; i < len(s.nodes); {
+		prev, node := s.nodes[i-1], &s.nodes[i]
+		overlap := prev.X + prev.Width - node.X
+		if overlap <= 0 {
+			break
+		}
+		node.X += overlap
+		node.Width -= overlap
+		if node.Width > 0 {
+			break
+		}
+		s.nodes = append(s.nodes[:i], s.nodes[i+1:]...)
+	}
+	for i := 1; i < len(s.nodes); {
+		if s.nodes[i-1].Y == s.nodes[i].Y {
+			s.nodes[i-1].Width += s.nodes[i].Width
+			s.nodes = append(s.nodes[:i], s.nodes[i+1:]...)
+		} else {
+			i++
+		}
+	}
+	return p, true
+}
+
+// PackAtlas combines images into as many pages as needed. Each image is
+// surrounded by Padding pixels, which are filled with its edge pixels when
+// Bleed is set to stop filtering from picking up neighbouring images.
+func PackAtlas(images map[string]image.Image, options AtlasOptions) (*Atlas, error) {
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := images[names[i]].Bounds().Size(), images[names[j]].Bounds().Size()
+		if a.Y != b.Y {
+			return a.Y > b.Y
+		}
+		if a.X != b.X {
+			return a.X > b.X
+		}
+		return names[i] < names[j]
+	})
+	atlas := &Atlas{Width: options.PageWidth, Height: options.PageHeight, Regions: make(map[string]AtlasRegion)}
+	var pages []*skyline
+	pad := options.Padding
+	for _, name := range names {
+		img := images[name]
+		size := img.Bounds().Size()
+		width, height := size.X+2*pad, size.Y+2*pad
+		if width > options.PageWidth || height > options.PageHeight {
+			return nil, fmt.Errorf("atlas: %s (%dx%d) does not fit on a %dx%d page", name, size.X, size.Y, options.PageWidth, options.PageHeight)
+		}
+		page, p, ok := 0, image.Point{}, false
+		for ; page < len(pages) && !ok; page++ {
+			p, ok = pages[page].insert(width, height)
+		}
+		if ok {
+			page--
+		} else {
+			pages = append(pages, newSkyline(options.PageWidth, options.PageHeight))
+			atlas.Images = append(atlas.Images, image.NewNRGBA(image.Rect(0, 0, options.PageWidth, options.PageHeight)))
+			p, _ = pages[page].insert(width, height)
+		}
+		r := image.Rectangle{Min: p, Max: p.Add(size)}.Add(image.Pt(pad, pad))
+		draw.Draw(atlas.Images[page], r, img, img.Bounds().Min, draw.Src)
+		if options.Bleed {
+			bleed(atlas.Images[page], r, pad)
+		}
+		atlas.Regions[name] = atlas.region(page, r)
+	}
+	return atlas, nil
+}
+
+func (atlas *Atlas) region(page int, r image.Rectangle) AtlasRegion {
+	w, h := float64(atlas.Width), float64(atlas.Height)
+	return AtlasRegion{
+		page, r.Min.X, r.Min.Y, r.Dx(), r.Dy(),
+		float64(r.Min.X) / w, float64(r.Min.Y) / h,
+		float64(r.Max.X) / w, float64(r.Max.Y) / h,
+	}
+}
+
+// bleed extends the edge pixels of r outwards by pad pixels.
+func bleed(page *image.NRGBA, r image.Rectangle, pad int) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		left, right := page.NRGBAAt(r.Min.X, y), page.NRGBAAt(r.Max.X-1, y)
+		for i := 1; i <= pad; i++ {
+			page.SetNRGBA(r.Min.X-i, y, left)
+			page.SetNRGBA(r.Max.X-1+i, y, right)
+		}
+	}
+	for x := r.Min.X - pad; x < r.Max.X+pad; x++ {
+		top, bottom := page.NRGBAAt(x, r.Min.Y), page.NRGBAAt(x, r.Max.Y-1)
+		for i := 1; i <= pad; i++ {
+			page.SetNRGBA(x, r.Min.Y-i, top)
+			page.SetNRGBA(x, r.Max.Y-1+i, bottom)
+		}
+	}
+}
+
+func (atlas *Atlas) Region(name string) (AtlasRegion, bool) {
+	region, ok := atlas.Regions[name]
+	return region, ok
+}
+
+func (atlas *Atlas) PagePath(page int) string {
+	if filepath.IsAbs(atlas.Pages[page]) {
+		return atlas.Pages[page]
+	}
+	return filepath.Join(atlas.Dir, atlas.Pages[page])
+}
+
+// Save writes the metadata to filename and each page as a PNG beside it,
+// named after the metadata file.
+func (atlas *Atlas) Save(filename string) error {
+	atlas.Dir = filepath.Dir(filename)
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	atlas.Pages = atlas.Pages[:0]
+	for i, page := range atlas.Images {
+		name := fmt.Sprintf("%s-%d.png", base, i)
+		err := SavePNG(filepath.Join(atlas.Dir, name), page)
+		if err != nil {
+			return err
+		}
+		atlas.Pages = append(atlas.Pages, name)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(atlas)
+}
+
+func LoadAtlas(filename string) (*Atlas, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	atlas := &Atlas{}
+	err = json.NewDecoder(file).Decode(atlas)
+	if err != nil {
+		return nil, err
+	}
+	atlas.Dir = filepath.Dir(filename)
+	return atlas, nil
+}
+
+// LoadTextures uploads each page, using the packed images when the atlas
+// was just built and the saved page files otherwise.
+func (atlas *Atlas) LoadTextures(options TextureOptions) ([]gl.Texture, error) {
+	var textures []gl.Texture
+	pages := len(atlas.Pages)
+	if atlas.Images != nil {
+		pages = len(atlas.Images)
+	}
+	for i := 0; i < pages; i++ {
+		texture := GenTexture()
+		textures = append(textures, texture)
+		if atlas.Images != nil {
+			UploadTexture(texture, atlas.Images[i], options)
+			continue
+		}
+		err := LoadTextureWithOptions(texture, atlas.PagePath(i), options)
+		if err != nil {
+			for _, t := range textures {
+				DeleteTexture(t)
+			}
+			return nil, err
+		}
+	}
+	return textures, nil
+}
@@ -0,0 +1,187 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// patterned returns an image whose every pixel differs, so misplaced or
+// misbled pixels show up.
+func patterned(width, height int, seed uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{seed, uint8(x), uint8(y), 255})
+		}
+	}
+	return img
+}
+
+func TestPackAtlasNoOverlap(t *testing.T) {
+	images := make(map[string]image.Image)
+	sizes := []image.Point{{10, 4}, {3, 3}, {7, 9}, {1, 1}, {12, 2}, {5, 5}, {8, 3}, {2, 11}, {6, 6}, {4, 7}}
+	for i, size := range sizes {
+		images[fmt.Sprint("image", i)] = patterned(size.X, size.Y, uint8(i))
+	}
+	const pad = 2
+	atlas, err := PackAtlas(images, AtlasOptions{PageWidth: 24, PageHeight: 24, Padding: pad})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atlas.Images) < 2 {
+		t.Errorf("packed onto %d pages, expected to spill onto a second", len(atlas.Images))
+	}
+	padded := make(map[string]image.Rectangle)
+	for name, region := range atlas.Regions {
+		r := image.Rect(region.X, region.Y, region.X+region.Width, region.Y+region.Height)
+		if r.Size() != images[name].Bounds().Size() {
+			t.Errorf("%s: region %v, image %v", name, r, images[name].Bounds())
+		}
+		outer := r.Inset(-pad)
+		if !outer.In(image.Rect(0, 0, atlas.Width, atlas.Height)) {
+			t.Errorf("%s: padded region %v leaves the page", name, outer)
+		}
+		for other, o := range padded {
+			if atlas.Regions[other].Page == region.Page && o.Overlaps(outer) {
+				t.Errorf("%s %v overlaps %s %v", name, outer, other, o)
+			}
+		}
+		padded[name] = outer
+		u0, v0 := float64(r.Min.X)/24, float64(r.Min.Y)/24
+		u1, v1 := float64(r.Max.X)/24, float64(r.Max.Y)/24
+		if region.U0 != u0 || region.V0 != v0 || region.U1 != u1 || region.V1 != v1 {
+			t.Errorf("%s: uv %v", name, region)
+		}
+		page := atlas.Images[region.Page]
+		src := images[name].(*image.NRGBA)
+		for y := 0; y < r.Dy(); y++ {
+			for x := 0; x < r.Dx(); x++ {
+				if got, want := page.NRGBAAt(r.Min.X+x, r.Min.Y+y), src.NRGBAAt(x, y); got != want {
+					t.Fatalf("%s: pixel %d,%d is %v, want %v", name, x, y, got, want)
+				}
+			}
+		}
+	}
+	if len(atlas.Regions) != len(images) {
+		t.Errorf("%d regions for %d images", len(atlas.Regions), len(images))
+	}
+}
+
+func TestPackAtlasBleed(t *testing.T) {
+	const pad = 3
+	src := patterned(4, 5, 7)
+	atlas, err := PackAtlas(map[string]image.Image{"a": src}, AtlasOptions{PageWidth: 16, PageHeight: 16, Padding: pad, Bleed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	region := atlas.Regions["a"]
+	page := atlas.Images[0]
+	clamp := func(v, max int) int {
+		if v < 0 {
+			return 0
+		}
+		if v >= max {
+			return max - 1
+		}
+		return v
+	}
+	// Every padding pixel repeats the nearest edge pixel, corners
+	// included.
+	for y := -pad; y < region.Height+pad; y++ {
+		for x := -pad; x < region.Width+pad; x++ {
+			got := page.NRGBAAt(region.X+x, region.Y+y)
+			want := src.NRGBAAt(clamp(x, region.Width), clamp(y, region.Height))
+			if got != want {
+				t.Errorf("pixel %d,%d is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestPackAtlasOversize(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    image.Point
+		padding int
+	}{
+		{"too wide", image.Pt(17, 4), 0},
+		{"too tall", image.Pt(4, 17), 0},
+		{"padding overflows", image.Pt(16, 16), 1},
+	}
+	for _, test := range tests {
+		images := map[string]image.Image{
+			"small": patterned(2, 2, 0),
+			"big":   patterned(test.size.X, test.size.Y, 1),
+		}
+		atlas, err := PackAtlas(images, AtlasOptions{PageWidth: 16, PageHeight: 16, Padding: test.padding})
+		if err == nil || !strings.Contains(err.Error(), "big") {
+			t.Errorf("%s: atlas %v error %v", test.name, atlas, err)
+		}
+	}
+}
+
+func TestAtlasSaveLoad(t *testing.T) {
+	images := make(map[string]image.Image)
+	for i, size := range []image.Point{{10, 10}, {12, 6}, {5, 9}, {8, 8}} {
+		images[fmt.Sprint("image", i)] = patterned(size.X, size.Y, uint8(i))
+	}
+	atlas, err := PackAtlas(images, AtlasOptions{PageWidth: 16, PageHeight: 16, Padding: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "sprites")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "ui.atlas.json")
+	if err := atlas.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadAtlas(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(atlas.Images) < 2 || len(loaded.Pages) != len(atlas.Images) {
+		t.Fatalf("saved %d pages, loaded %v", len(atlas.Images), loaded.Pages)
+	}
+	if loaded.Dir != dir || loaded.Width != 16 || loaded.Height != 16 || loaded.Images != nil {
+		t.Errorf("loaded %+v", loaded)
+	}
+	if !reflect.DeepEqual(loaded.Regions, atlas.Regions) {
+		t.Errorf("regions %v, want %v", loaded.Regions, atlas.Regions)
+	}
+	for i, page := range atlas.Images {
+		name := fmt.Sprintf("ui.atlas-%d.png", i)
+		if loaded.Pages[i] != name || loaded.PagePath(i) != filepath.Join(dir, name) {
+			t.Errorf("page %d is %q at %q, want %q", i, loaded.Pages[i], loaded.PagePath(i), name)
+		}
+		file, err := os.Open(loaded.PagePath(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := toNRGBA(img); !bytes.Equal(got.Pix, page.Pix) || got.Rect != page.Rect {
+			t.Errorf("page %d does not round trip", i)
+		}
+	}
+	absolute := filepath.Join(t.TempDir(), "page.png")
+	loaded.Pages[0] = absolute
+	if path := loaded.PagePath(0); path != absolute {
+		t.Errorf("absolute page resolved to %q", path)
+	}
+	if _, err := LoadAtlas(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing atlas loaded")
+	}
+}
@@ -0,0 +1,496 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+)
+
+// GLBackend is the set of gl functions used by the package. Calls go through
+// Backend so pipeline code can run against RecordingBackend without a GPU.
+type GLBackend interface {
+	ActiveTexture(texture gl.Enum)
+	AttachShader(program gl.Program, shader gl.Uint)
+	BindBuffer(target gl.Enum, buffer gl.Buffer)
+	BindBufferBase(target gl.Enum, index gl.Uint, buffer gl.Buffer)
+	BindFramebuffer(target gl.Enum, framebuffer gl.Framebuffer)
+	BindRenderbuffer(target gl.Enum, renderbuffer gl.Renderbuffer)
+	BindTexture(target gl.Enum, texture gl.Texture)
+	BindVertexArray(vao gl.VertexArrayObject)
+	BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 gl.Int, mask gl.Bitfield, filter gl.Enum)
+	BufferData(target gl.Enum, size gl.Sizeiptr, data gl.Pointer, usage gl.Enum)
+	BufferSubData(target gl.Enum, offset gl.Intptr, size gl.Sizeiptr, data gl.Pointer)
+	CheckFramebufferStatus(target gl.Enum) gl.Enum
+	ClientWaitSync(sync gl.Sync, flags gl.Bitfield, timeout gl.Uint64) gl.Enum
+	ColorMask(red, green, blue, alpha gl.Boolean)
+	CompileShader(shader gl.Uint)
+	CompressedTexImage2D(target gl.Enum, level gl.Int, internalFormat gl.Enum, width, height gl.Sizei, border gl.Int, imageSize gl.Sizei, data gl.Pointer)
+	CompressedTexImage3D(target gl.Enum, level gl.Int, internalFormat gl.Enum, width, height, depth gl.Sizei, border gl.Int, imageSize gl.Sizei, data gl.Pointer)
+	CreateProgram() gl.Program
+	CreateShader(shaderType gl.Enum) gl.Uint
+	DeleteBuffer(buffer gl.Buffer)
+	DeleteFramebuffer(framebuffer gl.Framebuffer)
+	DeleteProgram(program gl.Program)
+	DeleteRenderbuffer(renderbuffer gl.Renderbuffer)
+	DeleteShader(shader gl.Uint)
+	DeleteSync(sync gl.Sync)
+	DeleteTexture(texture gl.Texture)
+	DeleteVertexArray(vao gl.VertexArrayObject)
+	DepthFunc(function gl.Enum)
+	DepthMask(flag gl.Boolean)
+	DetachShader(program gl.Program, shader gl.Uint)
+	Disable(capability gl.Enum)
+	DisableVertexAttribArray(location gl.AttributeLocation)
+	DrawBuffer(mode gl.Enum)
+	DrawBuffers(n gl.Sizei, buffers *gl.Enum)
+	DrawElements(mode gl.Enum, count gl.Sizei, indexType gl.Enum, indices gl.Pointer)
+	Enable(capability gl.Enum)
+	EnableVertexAttribArray(location gl.AttributeLocation)
+	FenceSync(condition gl.Enum, flags gl.Bitfield) gl.Sync
+	FramebufferRenderbuffer(target, attachment, renderbuffertarget gl.Enum, renderbuffer gl.Renderbuffer)
+	FramebufferTexture2D(target, attachment, textarget gl.Enum, texture gl.Texture, level gl.Int)
+	GenBuffer() gl.Buffer
+	GenFramebuffer() gl.Framebuffer
+	GenRenderbuffer() gl.Renderbuffer
+	GenTexture() gl.Texture
+	GenVertexArray() gl.VertexArrayObject
+	GenerateMipmap(target gl.Enum)
+	GetActiveAttrib(program gl.Program, index gl.Uint) (string, gl.Int, gl.Enum)
+	GetActiveUniform(program gl.Program, index gl.Uint) (string, gl.Int, gl.Enum)
+	GetActiveUniformBlockName(program gl.Program, index gl.Uint) string
+	GetActiveUniformBlockiv(program gl.Program, index gl.Uint, pname gl.Enum, params *gl.Int)
+	GetAttribLocation(program gl.Program, name string) gl.AttributeLocation
+	GetError() gl.Enum
+	GetFloatv(pname gl.Enum, params *gl.Float)
+	GetIntegerv(pname gl.Enum, params *gl.Int)
+	GetProgramInfoLog(program gl.Program) string
+	GetProgramiv(program gl.Program, pname gl.Enum, params *gl.Int)
+	GetShaderInfoLog(shader gl.Uint) string
+	GetShaderiv(shader gl.Uint, pname gl.Enum, params *gl.Int)
+	GetString(name gl.Enum) string
+	GetStringi(name gl.Enum, index gl.Uint) string
+	GetUniformBlockIndex(program gl.Program, name string) gl.Uint
+	GetUniformLocation(program gl.Program, name string) gl.UniformLocation
+	LinkProgram(program gl.Program)
+	MapBufferRange(target gl.Enum, offset gl.Intptr, length gl.Sizeiptr, access gl.Bitfield) gl.Pointer
+	PixelStorei(pname gl.Enum, param gl.Int)
+	ReadBuffer(mode gl.Enum)
+	ReadPixels(x, y gl.Int, width, height gl.Sizei, format, xtype gl.Enum, pixels gl.Pointer)
+	RenderbufferStorage(target, internalFormat gl.Enum, width, height gl.Sizei)
+	RenderbufferStorageMultisample(target gl.Enum, samples gl.Sizei, internalFormat gl.Enum, width, height gl.Sizei)
+	ShaderSource(shader gl.Uint, sources []string)
+	StencilFunc(function gl.Enum, ref gl.Int, mask gl.Uint)
+	StencilOp(sfail, dpfail, dppass gl.Enum)
+	TexImage2D(target gl.Enum, level gl.Int, internalFormat gl.Int, width, height gl.Sizei, border gl.Int, format, xtype gl.Enum, pixels gl.Pointer)
+	TexImage2DMultisample(target gl.Enum, samples gl.Sizei, internalFormat gl.Int, width, height gl.Sizei, fixedSampleLocations gl.Boolean)
+	TexImage3D(target gl.Enum, level gl.Int, internalFormat gl.Int, width, height, depth gl.Sizei, border gl.Int, format, xtype gl.Enum, pixels gl.Pointer)
+	TexParameterf(target, pname gl.Enum, param gl.Float)
+	TexParameteri(target, pname gl.Enum, param gl.Int)
+	TexSubImage3D(target gl.Enum, level, xoffset, yoffset, zoffset gl.Int, width, height, depth gl.Sizei, format, xtype gl.Enum, pixels gl.Pointer)
+	Uniform1f(location gl.UniformLocation, v0 gl.Float)
+	Uniform1i(location gl.UniformLocation, v0 gl.Int)
+	Uniform1ui(location gl.UniformLocation, v0 gl.Uint)
+	Uniform2f(location gl.UniformLocation, v0, v1 gl.Float)
+	Uniform2ui(location gl.UniformLocation, v0, v1 gl.Uint)
+	Uniform3f(location gl.UniformLocation, v0, v1, v2 gl.Float)
+	Uniform3ui(location gl.UniformLocation, v0, v1, v2 gl.Uint)
+	Uniform4f(location gl.UniformLocation, v0, v1, v2, v3 gl.Float)
+	Uniform4ui(location gl.UniformLocation, v0, v1, v2, v3 gl.Uint)
+	UniformBlockBinding(program gl.Program, index, binding gl.Uint)
+	UniformMatrix3fv(location gl.UniformLocation, count gl.Sizei, transpose gl.Boolean, value *gl.Float)
+	UniformMatrix4fv(location gl.UniformLocation, count gl.Sizei, transpose gl.Boolean, value *gl.Float)
+	UnmapBuffer(target gl.Enum) gl.Boolean
+	UseProgram(program gl.Program)
+	VertexAttribIPointer(location gl.AttributeLocation, size gl.Int, xtype gl.Enum, stride gl.Sizei, pointer gl.Pointer)
+	VertexAttribPointer(location gl.AttributeLocation, size gl.Int, xtype gl.Enum, normalized gl.Boolean, stride gl.Sizei, pointer gl.Pointer)
+	Viewport(x, y gl.Int, width, height gl.Sizei)
+}
+
+var Backend GLBackend = NativeBackend{}
+
+// SetBackend replaces Backend and returns the previous one.
+func SetBackend(backend GLBackend) GLBackend {
+	previous := Backend
+	Backend = backend
+	return previous
+}
+
+type NativeBackend struct{}
+
+func (NativeBackend) ActiveTexture(texture gl.Enum) {
+	gl.ActiveTexture(texture)
+}
+
+func (NativeBackend) AttachShader(program gl.Program, shader gl.Uint) {
+	gl.AttachShader(program, shader)
+}
+
+func (NativeBackend) BindBuffer(target gl.Enum, buffer gl.Buffer) {
+	gl.BindBuffer(target, buffer)
+}
+
+func (NativeBackend) BindBufferBase(target gl.Enum, index gl.Uint, buffer gl.Buffer) {
+	gl.BindBufferBase(target, index, buffer)
+}
+
+func (NativeBackend) BindFramebuffer(target gl.Enum, framebuffer gl.Framebuffer) {
+	gl.BindFramebuffer(target, framebuffer)
+}
+
+func (NativeBackend) BindRenderbuffer(target gl.Enum, renderbuffer gl.Renderbuffer) {
+	gl.BindRenderbuffer(target, renderbuffer)
+}
+
+func (NativeBackend) BindTexture(target gl.Enum, texture gl.Texture) {
+	gl.BindTexture(target, texture)
+}
+
+func (NativeBackend) BindVertexArray(vao gl.VertexArrayObject) {
+	gl.BindVertexArray(vao)
+}
+
+func (NativeBackend) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 gl.Int, mask gl.Bitfield, filter gl.Enum) {
+	gl.BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func (NativeBackend) BufferData(target gl.Enum, size gl.Sizeiptr, data gl.Pointer, usage gl.Enum) {
+	gl.BufferData(target, size, data, usage)
+}
+
+func (NativeBackend) BufferSubData(target gl.Enum, offset gl.Intptr, size gl.Sizeiptr, data gl.Pointer) {
+	gl.BufferSubData(target, offset, size, data)
+}
+
+func (NativeBackend) CheckFramebufferStatus(target gl.Enum) gl.Enum {
+	return gl.CheckFramebufferStatus(target)
+}
+
+func (NativeBackend) ClientWaitSync(sync gl.Sync, flags gl.Bitfield, timeout gl.Uint64) gl.Enum {
+	return gl.ClientWaitSync(sync, flags, timeout)
+}
+
+func (NativeBackend) ColorMask(red, green, blue, alpha gl.Boolean) {
+	gl.ColorMask(red, green, blue, alpha)
+}
+
+func (NativeBackend) CompileShader(shader gl.Uint) {
+	gl.CompileShader(shader)
+}
+
+func (NativeBackend) CompressedTexImage2D(target gl.Enum, level gl.Int, internalFormat gl.Enum, width, height gl.Sizei, border gl.Int, imageSize gl.Sizei, data gl.Pointer) {
+	gl.CompressedTexImage2D(target, level, internalFormat, width, height, border, imageSize, data)
+}
+
+func (NativeBackend) CompressedTexImage3D(target gl.Enum, level gl.Int, internalFormat gl.Enum, width, height, depth gl.Sizei, border gl.Int, imageSize gl.Sizei, data gl.Pointer) {
+	gl.CompressedTexImage3D(target, level, internalFormat, width, height, depth, border, imageSize, data)
+}
+
+func (NativeBackend) CreateProgram() gl.Program {
+	return gl.CreateProgram()
+}
+
+func (NativeBackend) CreateShader(shaderType gl.Enum) gl.Uint {
+	return gl.CreateShader(shaderType)
+}
+
+func (NativeBackend) DeleteBuffer(buffer gl.Buffer) {
+	gl.DeleteBuffer(buffer)
+}
+
+func (NativeBackend) DeleteFramebuffer(framebuffer gl.Framebuffer) {
+	gl.DeleteFramebuffer(framebuffer)
+}
+
+func (NativeBackend) DeleteProgram(program gl.Program) {
+	gl.DeleteProgram(program)
+}
+
+func (NativeBackend) DeleteRenderbuffer(renderbuffer gl.Renderbuffer) {
+	gl.DeleteRenderbuffer(renderbuffer)
+}
+
+func (NativeBackend) DeleteShader(shader gl.Uint) {
+	gl.DeleteShader(shader)
+}
+
+func (NativeBackend) DeleteSync(sync gl.Sync) {
+	gl.DeleteSync(sync)
+}
+
+func (NativeBackend) DeleteTexture(texture gl.Texture) {
+	gl.DeleteTexture(texture)
+}
+
+func (NativeBackend) DeleteVertexArray(vao gl.VertexArrayObject) {
+	gl.DeleteVertexArray(vao)
+}
+
+func (NativeBackend) DepthFunc(function gl.Enum) {
+	gl.DepthFunc(function)
+}
+
+func (NativeBackend) DepthMask(flag gl.Boolean) {
+	gl.DepthMask(flag)
+}
+
+func (NativeBackend) DetachShader(program gl.Program, shader gl.Uint) {
+	gl.DetachShader(program, shader)
+}
+
+func (NativeBackend) Disable(capability gl.Enum) {
+	gl.Disable(capability)
+}
+
+func (NativeBackend) DisableVertexAttribArray(location gl.AttributeLocation) {
+	gl.DisableVertexAttribArray(location)
+}
+
+func (NativeBackend) DrawBuffer(mode gl.Enum) {
+	gl.DrawBuffer(mode)
+}
+
+func (NativeBackend) DrawBuffers(n gl.Sizei, buffers *gl.Enum) {
+	gl.DrawBuffers(n, buffers)
+}
+
+func (NativeBackend) DrawElements(mode gl.Enum, count gl.Sizei, indexType gl.Enum, indices gl.Pointer) {
+	gl.DrawElements(mode, count, indexType, indices)
+}
+
+func (NativeBackend) Enable(capability gl.Enum) {
+	gl.Enable(capability)
+}
+
+func (NativeBackend) EnableVertexAttribArray(location gl.AttributeLocation) {
+	gl.EnableVertexAttribArray(location)
+}
+
+func (NativeBackend) FenceSync(condition gl.Enum, flags gl.Bitfield) gl.Sync {
+	return gl.FenceSync(condition, flags)
+}
+
+func (NativeBackend) FramebufferRenderbuffer(target, attachment, renderbuffertarget gl.Enum, renderbuffer gl.Renderbuffer) {
+	gl.FramebufferRenderbuffer(target, attachment, renderbuffertarget, renderbuffer)
+}
+
+func (NativeBackend) FramebufferTexture2D(target, attachment, textarget gl.Enum, texture gl.Texture, level gl.Int) {
+	gl.FramebufferTexture2D(target, attachment, textarget, texture, level)
+}
+
+func (NativeBackend) GenBuffer() gl.Buffer {
+	return gl.GenBuffer()
+}
+
+func (NativeBackend) GenFramebuffer() gl.Framebuffer {
+	return gl.GenFramebuffer()
+}
+
+func (NativeBackend) GenRenderbuffer() gl.Renderbuffer {
+	return gl.GenRenderbuffer()
+}
+
+func (NativeBackend) GenTexture() gl.Texture {
+	return gl.GenTexture()
+}
+
+func (NativeBackend) GenVertexArray() gl.VertexArrayObject {
+	return gl.GenVertexArray()
+}
+
+func (NativeBackend) GenerateMipmap(target gl.Enum) {
+	gl.GenerateMipmap(target)
+}
+
+func (NativeBackend) GetActiveAttrib(program gl.Program, index gl.Uint) (string, gl.Int, gl.Enum) {
+	return gl.GetActiveAttrib(program, index)
+}
+
+func (NativeBackend) GetActiveUniform(program gl.Program, index gl.Uint) (string, gl.Int, gl.Enum) {
+	return gl.GetActiveUniform(program, index)
+}
+
+func (NativeBackend) GetActiveUniformBlockName(program gl.Program, index gl.Uint) string {
+	return gl.GetActiveUniformBlockName(program, index)
+}
+
+func (NativeBackend) GetActiveUniformBlockiv(program gl.Program, index gl.Uint, pname gl.Enum, params *gl.Int) {
+	gl.GetActiveUniformBlockiv(program, index, pname, params)
+}
+
+func (NativeBackend) GetAttribLocation(program gl.Program, name string) gl.AttributeLocation {
+	return gl.GetAttribLocation(program, name)
+}
+
+func (NativeBackend) GetError() gl.Enum {
+	return gl.GetError()
+}
+
+func (NativeBackend) GetFloatv(pname gl.Enum, params *gl.Float) {
+	gl.GetFloatv(pname, params)
+}
+
+func (NativeBackend) GetIntegerv(pname gl.Enum, params *gl.Int) {
+	gl.GetIntegerv(pname, params)
+}
+
+func (NativeBackend) GetProgramInfoLog(program gl.Program) string {
+	return gl.GetProgramInfoLog(program)
+}
+
+func (NativeBackend) GetProgramiv(program gl.Program, pname gl.Enum, params *gl.Int) {
+	gl.GetProgramiv(program, pname, params)
+}
+
+func (NativeBackend) GetShaderInfoLog(shader gl.Uint) string {
+	return gl.GetShaderInfoLog(shader)
+}
+
+func (NativeBackend) GetShaderiv(shader gl.Uint, pname gl.Enum, params *gl.Int) {
+	gl.GetShaderiv(shader, pname, params)
+}
+
+func (NativeBackend) GetString(name gl.Enum) string {
+	return gl.GetString(name)
+}
+
+func (NativeBackend) GetStringi(name gl.Enum, index gl.Uint) string {
+	return gl.GetStringi(name, index)
+}
+
+func (NativeBackend) GetUniformBlockIndex(program gl.Program, name string) gl.Uint {
+	return gl.GetUniformBlockIndex(program, name)
+}
+
+func (NativeBackend) GetUniformLocation(program gl.Program, name string) gl.UniformLocation {
+	return gl.GetUniformLocation(program, name)
+}
+
+func (NativeBackend) LinkProgram(program gl.Program) {
+	gl.LinkProgram(program)
+}
+
+func (NativeBackend) MapBufferRange(target gl.Enum, offset gl.Intptr, length gl.Sizeiptr, access gl.Bitfield) gl.Pointer {
+	return gl.MapBufferRange(target, offset, length, access)
+}
+
+func (NativeBackend) PixelStorei(pname gl.Enum, param gl.Int) {
+	gl.PixelStorei(pname, param)
+}
+
+func (NativeBackend) ReadBuffer(mode gl.Enum) {
+	gl.ReadBuffer(mode)
+}
+
+func (NativeBackend) ReadPixels(x, y gl.Int, width, height gl.Sizei, format, xtype gl.Enum, pixels gl.Pointer) {
+	gl.ReadPixels(x, y, width, height, format, xtype, pixels)
+}
+
+func (NativeBackend) RenderbufferStorage(target, internalFormat gl.Enum, width, height gl.Sizei) {
+	gl.RenderbufferStorage(target, internalFormat, width, height)
+}
+
+func (NativeBackend) RenderbufferStorageMultisample(target gl.Enum, samples gl.Sizei, internalFormat gl.Enum, width, height gl.Sizei) {
+	gl.RenderbufferStorageMultisample(target, samples, internalFormat, width, height)
+}
+
+func (NativeBackend) ShaderSource(shader gl.Uint, sources []string) {
+	gl.ShaderSource(shader, sources)
+}
+
+func (NativeBackend) StencilFunc(function gl.Enum, ref gl.Int, mask gl.Uint) {
+	gl.StencilFunc(function, ref, mask)
+}
+
+func (NativeBackend) StencilOp(sfail, dpfail, dppass gl.Enum) {
+	gl.StencilOp(sfail, dpfail, dppass)
+}
+
+func (NativeBackend) TexImage2D(target gl.Enum, level gl.Int, internalFormat gl.Int, width, height gl.Sizei, border gl.Int, format, xtype gl.Enum, pixels gl.Pointer) {
+	gl.TexImage2D(target, level, internalFormat, width, height, border, format, xtype, pixels)
+}
+
+func (NativeBackend) TexImage2DMultisample(target gl.Enum, samples gl.Sizei, internalFormat gl.Int, width, height gl.Sizei, fixedSampleLocations gl.Boolean) {
+	gl.TexImage2DMultisample(target, samples, internalFormat, width, height, fixedSampleLocations)
+}
+
+func (NativeBackend) TexImage3D(target gl.Enum, level gl.Int, internalFormat gl.Int, width, height, depth gl.Sizei, border gl.Int, format, xtype gl.Enum, pixels gl.Pointer) {
+	gl.TexImage3D(target, level, internalFormat, width, height, depth, border, format, xtype, pixels)
+}
+
+func (NativeBackend) TexParameterf(target, pname gl.Enum, param gl.Float) {
+	gl.TexParameterf(target, pname, param)
+}
+
+func (NativeBackend) TexParameteri(target, pname gl.Enum, param gl.Int) {
+	gl.TexParameteri(target, pname, param)
+}
+
+func (NativeBackend) TexSubImage3D(target gl.Enum, level, xoffset, yoffset, zoffset gl.Int, width, height, depth gl.Sizei, format, xtype gl.Enum, pixels gl.Pointer) {
+	gl.TexSubImage3D(target, level, xoffset, yoffset, zoffset, width, height, depth, format, xtype, pixels)
+}
+
+func (NativeBackend) Uniform1f(location gl.UniformLocation, v0 gl.Float) {
+	gl.Uniform1f(location, v0)
+}
+
+func (NativeBackend) Uniform1i(location gl.UniformLocation, v0 gl.Int) {
+	gl.Uniform1i(location, v0)
+}
+
+func (NativeBackend) Uniform1ui(location gl.UniformLocation, v0 gl.Uint) {
+	gl.Uniform1ui(location, v0)
+}
+
+func (NativeBackend) Uniform2f(location gl.UniformLocation, v0, v1 gl.Float) {
+	gl.Uniform2f(location, v0, v1)
+}
+
+func (NativeBackend) Uniform2ui(location gl.UniformLocation, v0, v1 gl.Uint) {
+	gl.Uniform2ui(location, v0, v1)
+}
+
+func (NativeBackend) Uniform3f(location gl.UniformLocation, v0, v1, v2 gl.Float) {
+	gl.Uniform3f(location, v0, v1, v2)
+}
+
+func (NativeBackend) Uniform3ui(location gl.UniformLocation, v0, v1, v2 gl.Uint) {
+	gl.Uniform3ui(location, v0, v1, v2)
+}
+
+func (NativeBackend) Uniform4f(location gl.UniformLocation, v0, v1, v2, v3 gl.Float) {
+	gl.Uniform4f(location, v0, v1, v2, v3)
+}
+
+func (NativeBackend) Uniform4ui(location gl.UniformLocation, v0, v1, v2, v3 gl.Uint) {
+	gl.Uniform4ui(location, v0, v1, v2, v3)
+}
+
+func (NativeBackend) UniformBlockBinding(program gl.Program, index, binding gl.Uint) {
+	gl.UniformBlockBinding(program, index, binding)
+}
+
+func (NativeBackend) UniformMatrix3fv(location gl.UniformLocation, count gl.Sizei, transpose gl.Boolean, value *gl.Float) {
+	gl.UniformMatrix3fv(location, count, transpose, value)
+}
+
+func (NativeBackend) UniformMatrix4fv(location gl.UniformLocation, count gl.Sizei, transpose gl.Boolean, value *gl.Float) {
+	gl.UniformMatrix4fv(location, count, transpose, value)
+}
+
+func (NativeBackend) UnmapBuffer(target gl.Enum) gl.Boolean {
+	return gl.UnmapBuffer(target)
+}
+
+func (NativeBackend) UseProgram(program gl.Program) {
+	gl.UseProgram(program)
+}
+
+func (NativeBackend) VertexAttribIPointer(location gl.AttributeLocation, size gl.Int, xtype gl.Enum, stride gl.Sizei, pointer gl.Pointer) {
+	gl.VertexAttribIPointer(location, size, xtype, stride, pointer)
+}
+
+func (NativeBackend) VertexAttribPointer(location gl.AttributeLocation, size gl.Int, xtype gl.Enum, normalized gl.Boolean, stride gl.Sizei, pointer gl.Pointer) {
+	gl.VertexAttribPointer(location, size, xtype, normalized, stride, pointer)
+}
+
+func (NativeBackend) Viewport(x, y gl.Int, width, height gl.Sizei) {
+	gl.Viewport(x, y, width, height)
+}
@@ -0,0 +1,118 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ProgramBinaryDriver supplies the ARB_get_program_binary entry points,
+// which gl32 does not bind since they are core only from GL 4.1.
+// NativeProgramBinaryDriver implements it for the current context.
+type ProgramBinaryDriver interface {
+	SetRetrievable(program gl.Program)
+	GetProgramBinary(program gl.Program) (gl.Enum, []byte, error)
+	ProgramBinary(program gl.Program, format gl.Enum, binary []byte) error
+}
+
+type ProgramBinaryCache struct {
+	Dir    string
+	Driver ProgramBinaryDriver
+	Vendor string
+}
+
+func NewProgramBinaryCache(dir string, driver ProgramBinaryDriver) *ProgramBinaryCache {
+	vendor := Backend.GetString(gl.VENDOR) + "\n" + Backend.GetString(gl.RENDERER) + "\n" + Backend.GetString(gl.VERSION)
+	return &ProgramBinaryCache{dir, driver, vendor}
+}
+
+func (cache *ProgramBinaryCache) Key(stages ProgramStages, options ShaderOptions) (string, error) {
+	return cache.KeyWith(ioutil.ReadFile, stages, options)
+}
+
+func (cache *ProgramBinaryCache) KeyWith(readFile ReadFileFunc, stages ProgramStages, options ShaderOptions) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(cache.Vendor))
+	for _, f := range stages.files() {
+		source, err := PreprocessShaderWith(readFile, f.Filename, options)
+		if err != nil {
+			return "", err
+		}
+		var header [4]byte
+		binary.LittleEndian.PutUint32(header[:], uint32(f.Stage))
+		hash.Write(header[:])
+		hash.Write([]byte(source.Source))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (cache *ProgramBinaryCache) path(key string) string {
+	return filepath.Join(cache.Dir, key+".bin")
+}
+
+func (cache *ProgramBinaryCache) Load(program gl.Program, key string) bool {
+	bytes, err := ioutil.ReadFile(cache.path(key))
+	if err != nil || len(bytes) < 4 {
+		return false
+	}
+	format := gl.Enum(binary.LittleEndian.Uint32(bytes))
+	err = cache.Driver.ProgramBinary(program, format, bytes[4:])
+	var ok gl.Int
+	if err == nil {
+		Backend.GetProgramiv(program, gl.LINK_STATUS, &ok)
+	}
+	if ok == 0 {
+		os.Remove(cache.path(key))
+		return false
+	}
+	return true
+}
+
+func (cache *ProgramBinaryCache) Store(program gl.Program, key string) error {
+	format, data, err := cache.Driver.GetProgramBinary(program)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return errors.New("driver returned an empty program binary")
+	}
+	err = os.MkdirAll(cache.Dir, 0755)
+	if err != nil {
+		return err
+	}
+	bytes := make([]byte, 4+len(data))
+	binary.LittleEndian.PutUint32(bytes, uint32(format))
+	copy(bytes[4:], data)
+	return writeFileAtomic(cache.path(key), bytes)
+}
+
+// writeFileAtomic writes through a temporary file so a crash never leaves
+// a truncated file behind.
+func writeFileAtomic(filename string, data []byte) error {
+	file, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type fakeBinaryDriver struct {
+	format gl.Enum
+	binary []byte
+	loaded []byte
+}
+
+func (d *fakeBinaryDriver) SetRetrievable(program gl.Program) {}
+
+func (d *fakeBinaryDriver) GetProgramBinary(program gl.Program) (gl.Enum, []byte, error) {
+	return d.format, d.binary, nil
+}
+
+func (d *fakeBinaryDriver) ProgramBinary(program gl.Program, format gl.Enum, binary []byte) error {
+	if format != d.format {
+		return fmt.Errorf("unknown format %#x", format)
+	}
+	d.loaded = binary
+	return nil
+}
+
+func TestProgramBinaryCache(t *testing.T) {
+	defer SetBackend(SetBackend(NewRecordingBackend()))
+	dir, err := ioutil.TempDir("", "binarycache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	driver := &fakeBinaryDriver{format: 0x1234, binary: []byte("program")}
+	cache := &ProgramBinaryCache{dir, driver, "vendor"}
+	if cache.Load(1, "key") {
+		t.Fatal("loaded a missing binary")
+	}
+	err = cache.Store(1, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "key.bin" {
+		t.Errorf("cache directory holds %v, want only key.bin", files)
+	}
+	if !cache.Load(2, "key") || !bytes.Equal(driver.loaded, driver.binary) {
+		t.Errorf("loaded %q, want %q", driver.loaded, driver.binary)
+	}
+}
+
+func TestProgramBinaryCacheRejected(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	dir := t.TempDir()
+	files := memoryFiles(map[string]string{"a.vert": "void main() {}\n", "a.frag": "void main() {}\n"})
+	stages := ProgramStages{Vertex: "a.vert", Fragment: "a.frag"}
+	build := func(driver *fakeBinaryDriver) *ShaderLibrary {
+		lib := NewShaderLibrary()
+		lib.ReadFile = files
+		lib.BinaryCache = &ProgramBinaryCache{dir, driver, "vendor"}
+		if err := lib.BuildProgram("a", stages); err != nil {
+			t.Fatal(err)
+		}
+		return &lib
+	}
+	build(&fakeBinaryDriver{format: 0x1234, binary: []byte("old")})
+	key, err := (&ProgramBinaryCache{dir, nil, "vendor"}).KeyWith(files, stages, DefaultShaderOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An updated driver no longer accepts the old format, so the program
+	// is compiled again and the cache rewritten with the new binary.
+	r.Reset()
+	driver := &fakeBinaryDriver{format: 0x5678, binary: []byte("new")}
+	lib := build(driver)
+	if driver.loaded != nil || r.Count("CompileShader") != 2 || r.Count("LinkProgram") != 1 {
+		t.Errorf("loaded %q, calls %v", driver.loaded, r.Names())
+	}
+	if r.Count("DeleteProgram") != 1 {
+		t.Errorf("rejected program deleted %d times", r.Count("DeleteProgram"))
+	}
+	if program, _ := lib.GetProgram("a"); !r.Live[Resource{ProgramResource, gl.Uint(program)}] {
+		t.Errorf("program %d not live", program)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, key+".bin"))
+	if err != nil || !bytes.Equal(data, []byte{0x78, 0x56, 0, 0, 'n', 'e', 'w'}) {
+		t.Errorf("cache holds %q error %v", data, err)
+	}
+	// The rewritten binary loads without compiling.
+	r.Reset()
+	build(driver)
+	if !bytes.Equal(driver.loaded, []byte("new")) || r.Count("CompileShader") != 0 {
+		t.Errorf("loaded %q, calls %v", driver.loaded, r.Names())
+	}
+}
@@ -0,0 +1,106 @@
+package render
+
+/*
+#include <stdint.h>
+
+#ifdef _WIN32
+#define GLUTIL_APIENTRY __stdcall
+#else
+#define GLUTIL_APIENTRY
+#endif
+
+typedef void (GLUTIL_APIENTRY *getProgramBinaryProc)(unsigned int, int, int *, unsigned int *, void *);
+typedef void (GLUTIL_APIENTRY *programBinaryProc)(unsigned int, unsigned int, const void *, int);
+typedef void (GLUTIL_APIENTRY *programParameteriProc)(unsigned int, unsigned int, int);
+
+static void getProgramBinary(uintptr_t proc, unsigned int program, int size, int *length, unsigned int *format, void *binary) {
+	((getProgramBinaryProc)proc)(program, size, length, format, binary);
+}
+
+static void programBinary(uintptr_t proc, unsigned int program, unsigned int format, const void *binary, int length) {
+	((programBinaryProc)proc)(program, format, binary, length);
+}
+
+static void programParameteri(uintptr_t proc, unsigned int program, unsigned int pname, int value) {
+	((programParameteriProc)proc)(program, pname, value);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"unsafe"
+)
+
+// ARB_get_program_binary is not part of gl32.
+const (
+	PROGRAM_BINARY_RETRIEVABLE_HINT = 0x8257
+	PROGRAM_BINARY_LENGTH           = 0x8741
+)
+
+// NativeProgramBinaryDriver calls the ARB_get_program_binary entry points
+// of the current context.
+type NativeProgramBinaryDriver struct {
+	getProgramBinary  uintptr
+	programBinary     uintptr
+	programParameteri uintptr
+}
+
+// NewNativeProgramBinaryDriver resolves the entry points with
+// getProcAddress, such as glfw.GetProcAddress, once a context is current.
+// It fails when the driver does not provide them.
+func NewNativeProgramBinaryDriver(getProcAddress func(name string) uintptr) (*NativeProgramBinaryDriver, error) {
+	driver := &NativeProgramBinaryDriver{
+		getProcAddress("glGetProgramBinary"),
+		getProcAddress("glProgramBinary"),
+		getProcAddress("glProgramParameteri"),
+	}
+	if driver.getProgramBinary == 0 || driver.programBinary == 0 || driver.programParameteri == 0 {
+		return nil, fmt.Errorf("program binaries are not supported by this driver")
+	}
+	return driver, nil
+}
+
+func (d *NativeProgramBinaryDriver) SetRetrievable(program gl.Program) {
+	C.programParameteri(C.uintptr_t(d.programParameteri), C.uint(program), PROGRAM_BINARY_RETRIEVABLE_HINT, 1)
+}
+
+func (d *NativeProgramBinaryDriver) GetProgramBinary(program gl.Program) (gl.Enum, []byte, error) {
+	var size gl.Int
+	Backend.GetProgramiv(program, PROGRAM_BINARY_LENGTH, &size)
+	if size <= 0 {
+		return 0, nil, fmt.Errorf("program has no binary")
+	}
+	data := make([]byte, size)
+	var length C.int
+	var format C.uint
+	C.getProgramBinary(C.uintptr_t(d.getProgramBinary), C.uint(program), C.int(size), &length, &format, unsafe.Pointer(&data[0]))
+	err := glError()
+	if err != nil {
+		return 0, nil, err
+	}
+	return gl.Enum(format), data[:length], nil
+}
+
+func (d *NativeProgramBinaryDriver) ProgramBinary(program gl.Program, format gl.Enum, binary []byte) error {
+	if len(binary) == 0 {
+		return fmt.Errorf("empty program binary")
+	}
+	C.programBinary(C.uintptr_t(d.programBinary), C.uint(program), C.uint(format), unsafe.Pointer(&binary[0]), C.int(len(binary)))
+	return glError()
+}
+
+// glError drains the error queue and returns the first error, if any.
+func glError() error {
+	first := gl.Enum(gl.NO_ERROR)
+	for err := Backend.GetError(); err != gl.NO_ERROR; err = Backend.GetError() {
+		if first == gl.NO_ERROR {
+			first = err
+		}
+	}
+	if first != gl.NO_ERROR {
+		return fmt.Errorf("gl error %#x", first)
+	}
+	return nil
+}
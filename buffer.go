@@ -0,0 +1,150 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"time"
+	"unsafe"
+)
+
+type Buffer struct {
+	Id     gl.Buffer
+	Target gl.Enum
+	Usage  gl.Enum
+	Size   int
+}
+
+func NewBuffer(target, usage gl.Enum) *Buffer {
+	return &Buffer{GenBuffer(), target, usage, 0}
+}
+
+func NewBufferData(target, usage gl.Enum, data interface{}) *Buffer {
+	buffer := NewBuffer(target, usage)
+	buffer.SetData(data)
+	return buffer
+}
+
+func (b *Buffer) Bind() {
+	Backend.BindBuffer(b.Target, b.Id)
+}
+
+func (b *Buffer) SetData(data interface{}) {
+	ptr, size := ArrayPtr(data)
+	b.Bind()
+	Backend.BufferData(b.Target, size, ptr, b.Usage)
+	b.Size = int(size)
+}
+
+func (b *Buffer) Allocate(size int) {
+	b.Bind()
+	Backend.BufferData(b.Target, gl.Sizeiptr(size), nil, b.Usage)
+	b.Size = size
+}
+
+func (b *Buffer) SubData(offset int, data interface{}) {
+	ptr, size := ArrayPtr(data)
+	if offset < 0 || offset+int(size) > b.Size {
+		panic(fmt.Sprintf("buffer update [%d, %d) outside buffer of size %d", offset, offset+int(size), b.Size))
+	}
+	b.Bind()
+	Backend.BufferSubData(b.Target, gl.Intptr(offset), size, ptr)
+}
+
+// Orphan detaches the current storage so the driver does not stall on draws
+// still reading it.
+func (b *Buffer) Orphan() {
+	b.Allocate(b.Size)
+}
+
+func (b *Buffer) Update(data interface{}) {
+	_, size := ArrayPtr(data)
+	if int(size) > b.Size {
+		b.SetData(data)
+		return
+	}
+	b.Orphan()
+	b.SubData(0, data)
+}
+
+func BindArrayDataUsage(buffer gl.Buffer, data interface{}, usage gl.Enum) {
+	ptr, size := ArrayPtr(data)
+	Backend.BindBuffer(gl.ARRAY_BUFFER, buffer)
+	Backend.BufferData(gl.ARRAY_BUFFER, size, ptr, usage)
+}
+
+// RingBuffer streams per frame data through one buffer split into segments.
+// Persistent mapping needs GL 4.4, so each segment is mapped unsynchronized
+// and guarded by a fence until the GPU has finished drawing from it.
+type RingBuffer struct {
+	Buffer      *Buffer
+	SegmentSize int
+	fences      []gl.Sync
+	current     int
+	mapped      bool
+}
+
+func NewRingBuffer(target gl.Enum, segmentSize, segments int) *RingBuffer {
+	buffer := NewBuffer(target, gl.STREAM_DRAW)
+	buffer.Allocate(segmentSize * segments)
+	return &RingBuffer{buffer, segmentSize, make([]gl.Sync, segments), 0, false}
+}
+
+func (r *RingBuffer) Offset() int {
+	return r.current * r.SegmentSize
+}
+
+func (r *RingBuffer) Map() []byte {
+	if r.mapped {
+		panic("ring buffer segment already mapped")
+	}
+	fence := r.fences[r.current]
+	if fence != nil {
+		waitSync(fence)
+		Backend.DeleteSync(fence)
+		r.fences[r.current] = nil
+	}
+	r.Buffer.Bind()
+	access := gl.Bitfield(gl.MAP_WRITE_BIT | gl.MAP_INVALIDATE_RANGE_BIT | gl.MAP_UNSYNCHRONIZED_BIT)
+	ptr := Backend.MapBufferRange(r.Buffer.Target, gl.Intptr(r.Offset()), gl.Sizeiptr(r.SegmentSize), access)
+	if ptr == nil {
+		PanicOnError()
+		panic("failed to map ring buffer segment")
+	}
+	r.mapped = true
+	return unsafe.Slice((*byte)(ptr), r.SegmentSize)
+}
+
+// syncTimeout is how long each wait for a fence blocks, in nanoseconds.
+const syncTimeout = gl.Uint64(time.Second)
+
+// waitSync blocks until the fence is signaled. Only the first wait flushes,
+// since the fence is in the command stream from then on.
+func waitSync(fence gl.Sync) {
+	flags := gl.Bitfield(gl.SYNC_FLUSH_COMMANDS_BIT)
+	for {
+		switch Backend.ClientWaitSync(fence, flags, syncTimeout) {
+		case gl.ALREADY_SIGNALED, gl.CONDITION_SATISFIED:
+			return
+		case gl.WAIT_FAILED:
+			PanicOnError()
+			panic("failed to wait for ring buffer fence")
+		}
+		flags = 0
+	}
+}
+
+func (r *RingBuffer) Unmap() {
+	r.Buffer.Bind()
+	Backend.UnmapBuffer(r.Buffer.Target)
+	r.mapped = false
+}
+
+// Advance fences the segment written this frame and moves to the next. Call
+// it after the draws that read the segment have been issued.
+func (r *RingBuffer) Advance() {
+	if r.mapped {
+		r.Unmap()
+	}
+	r.fences[r.current] = Backend.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	r.current = (r.current + 1) % len(r.fences)
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"testing"
+)
+
+func TestRingBufferWaitsForFence(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	ring := NewRingBuffer(gl.ARRAY_BUFFER, 16, 1)
+	ring.Map()
+	ring.Advance()
+	r.Reset()
+	r.SyncResults = []gl.Enum{gl.TIMEOUT_EXPIRED, gl.TIMEOUT_EXPIRED, gl.CONDITION_SATISFIED}
+	ring.Map()
+	var flags []gl.Bitfield
+	for _, call := range r.Calls {
+		if call.Name == "ClientWaitSync" {
+			flags = append(flags, call.Args[1].(gl.Bitfield))
+			if call.Args[2].(gl.Uint64) != syncTimeout {
+				t.Errorf("waited with timeout %v", call.Args[2])
+			}
+		}
+	}
+	want := []gl.Bitfield{gl.SYNC_FLUSH_COMMANDS_BIT, 0, 0}
+	if len(flags) != len(want) || flags[0] != want[0] || flags[1] != want[1] || flags[2] != want[2] {
+		t.Errorf("waited with flags %v, want %v", flags, want)
+	}
+	if r.Count("DeleteSync") != 1 {
+		t.Errorf("fence deleted %d times", r.Count("DeleteSync"))
+	}
+}
+
+func TestRingBufferWaitFailed(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	ring := NewRingBuffer(gl.ARRAY_BUFFER, 16, 1)
+	ring.Map()
+	ring.Advance()
+	r.SyncResults = []gl.Enum{gl.WAIT_FAILED}
+	defer func() {
+		if recover() == nil {
+			t.Error("Map did not panic when the wait failed")
+		}
+	}()
+	ring.Map()
+}
@@ -3,7 +3,9 @@ package render
 import (
    "os"
    "encoding/json"
-	// "fmt"
+   "io"
+   "io/fs"
+	"fmt"
 	glm "github.com/Jragonmiris/mathgl"
 	glfw "github.com/go-gl/glfw3"
 	"reflect"
@@ -209,24 +211,41 @@ func LoadConfiguration(confFile string, constants interface{}, bindings *Control
    file, err := os.Open(confFile)
    if err == nil {
       defer file.Close()
-      decoder := json.NewDecoder(file)
-      root := map[string]interface{}{}
-      err = decoder.Decode(&root)
-      if err != nil { return err }
-      if constants, ok := root["constants"]; ok {
-         bytes, err := json.Marshal(constants)
-         if err != nil { return err }
-         err = json.Unmarshal(bytes, &constants)
-         if err != nil { return err }
-      }
-      if controls, ok := root["controls"]; ok {
-         sc := make(map[string]string)
-         for k, v := range controls.(map[string]interface{}) {
-            sc[k] = v.(string)
-         }
-         bindings.Apply(receiver, sc)
-      }
+      err = LoadConfigurationFrom(file, constants, bindings, receiver)
    }
    return err
 }
 
+func LoadConfigurationFS(fsys fs.FS, name string, constants interface{}, bindings *ControlBindings, receiver interface{}) error {
+   file, err := fsys.Open(name)
+   if err == nil {
+      defer file.Close()
+      err = LoadConfigurationFrom(file, constants, bindings, receiver)
+   }
+   return err
+}
+
+func LoadConfigurationFrom(r io.Reader, constants interface{}, bindings *ControlBindings, receiver interface{}) error {
+   decoder := json.NewDecoder(r)
+   root := map[string]interface{}{}
+   err := decoder.Decode(&root)
+   if err != nil { return err }
+   if section, ok := root["constants"]; ok && constants != nil {
+      bytes, err := json.Marshal(section)
+      if err != nil { return err }
+      err = json.Unmarshal(bytes, constants)
+      if err != nil { return err }
+   }
+   if section, ok := root["controls"]; ok {
+      controls, ok := section.(map[string]interface{})
+      if !ok { return fmt.Errorf("config: controls must be an object, got %T", section) }
+      sc := make(map[string]string)
+      for k, v := range controls {
+         name, ok := v.(string)
+         if !ok { return fmt.Errorf("config: control %q must name an action, got %T", k, v) }
+         sc[k] = name
+      }
+      bindings.Apply(receiver, sc)
+   }
+   return nil
+}
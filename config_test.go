@@ -0,0 +1,58 @@
+package render
+
+import (
+	glfw "github.com/go-gl/glfw3"
+	"strings"
+	"testing"
+)
+
+type testConstants struct {
+	Speed float64
+	Name  string
+}
+
+type testReceiver struct {
+	jumps int
+}
+
+func (r *testReceiver) Jump() {
+	r.jumps++
+}
+
+func TestLoadConfigurationFrom(t *testing.T) {
+	constants := testConstants{Speed: 1, Name: "default"}
+	var bindings ControlBindings
+	bindings.ResetBindings()
+	receiver := &testReceiver{}
+	conf := `{"constants": {"Speed": 2.5}, "controls": {"J": "Jump"}}`
+	err := LoadConfigurationFrom(strings.NewReader(conf), &constants, &bindings, receiver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if constants != (testConstants{2.5, "default"}) {
+		t.Errorf("constants %+v", constants)
+	}
+	bindings.DoKeyAction(glfw.Key('J'), glfw.Press)
+	if receiver.jumps != 1 {
+		t.Errorf("J jumped %d times", receiver.jumps)
+	}
+}
+
+func TestLoadConfigurationFromErrors(t *testing.T) {
+	tests := []struct {
+		name, conf, err string
+	}{
+		{"controls not an object", `{"controls": ["J"]}`, "controls must be an object"},
+		{"control not a name", `{"controls": {"J": 3}}`, `control "J"`},
+		{"constants mismatch", `{"constants": {"Speed": "fast"}}`, "Speed"},
+	}
+	for _, test := range tests {
+		var constants testConstants
+		var bindings ControlBindings
+		bindings.ResetBindings()
+		err := LoadConfigurationFrom(strings.NewReader(test.conf), &constants, &bindings, &testReceiver{})
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+)
+
+// CubeFaces lists the cube map targets in the order faces are given to
+// the cube map loaders: +x, -x, +y, -y, +z, -z.
+var CubeFaces = [6]gl.Enum{
+	gl.TEXTURE_CUBE_MAP_POSITIVE_X,
+	gl.TEXTURE_CUBE_MAP_NEGATIVE_X,
+	gl.TEXTURE_CUBE_MAP_POSITIVE_Y,
+	gl.TEXTURE_CUBE_MAP_NEGATIVE_Y,
+	gl.TEXTURE_CUBE_MAP_POSITIVE_Z,
+	gl.TEXTURE_CUBE_MAP_NEGATIVE_Z,
+}
+
+type cubeCell struct {
+	X, Y    int
+	Rotated bool
+}
+
+// Face cells for each layout, in CubeFaces order, measured in face sizes.
+var (
+	horizontalCross = [6]cubeCell{{2, 1, false}, {0, 1, false}, {1, 0, false}, {1, 2, false}, {1, 1, false}, {3, 1, false}}
+	verticalCross   = [6]cubeCell{{2, 1, false}, {0, 1, false}, {1, 0, false}, {1, 2, false}, {1, 1, false}, {1, 3, true}}
+	horizontalStrip = [6]cubeCell{{0, 0, false}, {1, 0, false}, {2, 0, false}, {3, 0, false}, {4, 0, false}, {5, 0, false}}
+	verticalStrip   = [6]cubeCell{{0, 0, false}, {0, 1, false}, {0, 2, false}, {0, 3, false}, {0, 4, false}, {0, 5, false}}
+)
+
+// SplitCubeLayout cuts a single image into six faces. The layout is chosen
+// from the aspect ratio: 4:3 and 3:4 are crosses, 6:1 and 1:6 are strips.
+func SplitCubeLayout(img image.Image) ([6]image.Image, error) {
+	var faces [6]image.Image
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	var layout [6]cubeCell
+	var size int
+	switch {
+	case w == 0 || h == 0:
+		return faces, fmt.Errorf("cube map: empty image")
+	case w*3 == h*4:
+		layout, size = horizontalCross, w/4
+	case w*4 == h*3:
+		layout, size = verticalCross, w/3
+	case w == h*6:
+		layout, size = horizontalStrip, h
+	case w*6 == h:
+		layout, size = verticalStrip, w
+	default:
+		return faces, fmt.Errorf("cube map: unrecognised layout %dx%d", w, h)
+	}
+	for i, cell := range layout {
+		min := bounds.Min.Add(image.Pt(cell.X*size, cell.Y*size))
+		face := subImage(img, image.Rectangle{Min: min, Max: min.Add(image.Pt(size, size))})
+		if cell.Rotated {
+			face = rotate180(face)
+		}
+		faces[i] = face
+	}
+	return faces, nil
+}
+
+func subImage(img image.Image, r image.Rectangle) image.Image {
+	if s, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r)
+	}
+	return toNRGBA(img).SubImage(r.Sub(img.Bounds().Min))
+}
+
+func rotate180(img image.Image) image.Image {
+	src := toNRGBA(img)
+	bounds := src.Bounds()
+	dst := image.NewNRGBA(bounds)
+	w, h := bounds.Dx(), bounds.Dy()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.SetNRGBA(w-1-x, h-1-y, src.NRGBAAt(x, y))
+		}
+	}
+	return dst
+}
+
+func UploadCubeMap(texture gl.Texture, faces [6]image.Image, options TextureOptions) error {
+	size := faces[0].Bounds().Size()
+	if size.X != size.Y {
+		return fmt.Errorf("cube map: faces must be square, got %v", size)
+	}
+	layers, err := layerPixels(faces[:], options)
+	if err != nil {
+		return err
+	}
+	for i := range layers {
+		layers[i] = swizzled(layers[i])
+	}
+	Backend.BindTexture(gl.TEXTURE_CUBE_MAP, texture)
+	options.SetParameters(gl.TEXTURE_CUBE_MAP)
+	SetSwizzle(gl.TEXTURE_CUBE_MAP, layers[0])
+	for i, p := range layers {
+		TexImage2D(CubeFaces[i], 0, p)
+	}
+	options.GenerateMipmaps(gl.TEXTURE_CUBE_MAP)
+	return nil
+}
+
+func LoadCubeMap(texture gl.Texture, filenames [6]string, options TextureOptions) error {
+	images, err := DecodeImages(filenames[:])
+	if err != nil {
+		return err
+	}
+	var faces [6]image.Image
+	copy(faces[:], images)
+	return UploadCubeMap(texture, faces, options)
+}
+
+func LoadCubeMapLayout(texture gl.Texture, filename string, options TextureOptions) error {
+	img, err := DecodeImage(filename)
+	if err != nil {
+		return err
+	}
+	faces, err := SplitCubeLayout(img)
+	if err != nil {
+		return err
+	}
+	return UploadCubeMap(texture, faces, options)
+}
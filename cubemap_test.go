@@ -0,0 +1,69 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// coordinates returns an image whose pixels hold their own coordinates,
+// relative to the image's origin.
+func coordinates(r image.Rectangle) *image.NRGBA {
+	img := image.NewNRGBA(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(x - r.Min.X), uint8(y - r.Min.Y), 0, 255})
+		}
+	}
+	return img
+}
+
+func TestSplitCubeLayout(t *testing.T) {
+	const size = 2
+	tests := []struct {
+		name    string
+		bounds  image.Rectangle
+		cells   [6]image.Point
+		rotated int
+	}{
+		{"horizontal cross", image.Rect(0, 0, 8, 6), [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}}, -1},
+		{"offset horizontal cross", image.Rect(3, 5, 11, 11), [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {3, 1}}, -1},
+		{"vertical cross", image.Rect(0, 0, 6, 8), [6]image.Point{{2, 1}, {0, 1}, {1, 0}, {1, 2}, {1, 1}, {1, 3}}, 5},
+		{"horizontal strip", image.Rect(0, 0, 12, 2), [6]image.Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}}, -1},
+		{"vertical strip", image.Rect(0, 0, 2, 12), [6]image.Point{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {0, 4}, {0, 5}}, -1},
+	}
+	for _, test := range tests {
+		faces, err := SplitCubeLayout(coordinates(test.bounds))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		for i, face := range faces {
+			bounds := face.Bounds()
+			if bounds.Size() != image.Pt(size, size) {
+				t.Errorf("%s: face %d is %v", test.name, i, bounds)
+				continue
+			}
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					sx, sy := x, y
+					if i == test.rotated {
+						sx, sy = size-1-x, size-1-y
+					}
+					want := color.NRGBA{uint8(test.cells[i].X*size + sx), uint8(test.cells[i].Y*size + sy), 0, 255}
+					if got := color.NRGBAModel.Convert(face.At(bounds.Min.X+x, bounds.Min.Y+y)); got != want {
+						t.Errorf("%s: face %d pixel %d,%d is %v, want %v", test.name, i, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSplitCubeLayoutUnrecognised(t *testing.T) {
+	for _, size := range []image.Point{{4, 4}, {8, 2}, {0, 0}} {
+		if _, err := SplitCubeLayout(coordinates(image.Rectangle{Max: size})); err == nil {
+			t.Errorf("%v split without error", size)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package render
+
+import (
+	"encoding/binary"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+)
+
+var ddsMagic = []byte("DDS ")
+
+const (
+	ddsPixelFormatAlpha     = 0x1
+	ddsPixelFormatFourCC    = 0x4
+	ddsPixelFormatRGB       = 0x40
+	ddsPixelFormatLuminance = 0x20000
+	ddsCubeMap              = 0x200
+	ddsVolume               = 0x200000
+	dx10CubeMap             = 0x4
+	dx10Texture3D           = 4
+)
+
+var fourCCFormats = map[string]textureFormat{
+	"DXT1":          compressedFormat(COMPRESSED_RGB_S3TC_DXT1_EXT, 8),
+	"DXT3":          compressedFormat(COMPRESSED_RGBA_S3TC_DXT3_EXT, 16),
+	"DXT5":          compressedFormat(COMPRESSED_RGBA_S3TC_DXT5_EXT, 16),
+	"ATI1":          compressedFormat(COMPRESSED_RED_RGTC1, 8),
+	"BC4U":          compressedFormat(COMPRESSED_RED_RGTC1, 8),
+	"BC4S":          compressedFormat(COMPRESSED_SIGNED_RED_RGTC1, 8),
+	"ATI2":          compressedFormat(COMPRESSED_RG_RGTC2, 16),
+	"BC5U":          compressedFormat(COMPRESSED_RG_RGTC2, 16),
+	"BC5S":          compressedFormat(COMPRESSED_SIGNED_RG_RGTC2, 16),
+	"q\x00\x00\x00": {gl.RGBA16F, gl.RGBA, gl.HALF_FLOAT, 0, 8},
+	"t\x00\x00\x00": {gl.RGBA32F, gl.RGBA, gl.FLOAT, 0, 16},
+}
+
+var dxgiFormats = map[uint32]textureFormat{
+	2:  {gl.RGBA32F, gl.RGBA, gl.FLOAT, 0, 16},
+	10: {gl.RGBA16F, gl.RGBA, gl.HALF_FLOAT, 0, 8},
+	28: {gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE, 0, 4},
+	29: {gl.SRGB8_ALPHA8, gl.RGBA, gl.UNSIGNED_BYTE, 0, 4},
+	49: {gl.RG8, gl.RG, gl.UNSIGNED_BYTE, 0, 2},
+	61: {gl.R8, gl.RED, gl.UNSIGNED_BYTE, 0, 1},
+	71: compressedFormat(COMPRESSED_RGBA_S3TC_DXT1_EXT, 8),
+	72: compressedFormat(COMPRESSED_SRGB_ALPHA_S3TC_DXT1_EXT, 8),
+	74: compressedFormat(COMPRESSED_RGBA_S3TC_DXT3_EXT, 16),
+	75: compressedFormat(COMPRESSED_SRGB_ALPHA_S3TC_DXT3_EXT, 16),
+	77: compressedFormat(COMPRESSED_RGBA_S3TC_DXT5_EXT, 16),
+	78: compressedFormat(COMPRESSED_SRGB_ALPHA_S3TC_DXT5_EXT, 16),
+	80: compressedFormat(COMPRESSED_RED_RGTC1, 8),
+	81: compressedFormat(COMPRESSED_SIGNED_RED_RGTC1, 8),
+	83: compressedFormat(COMPRESSED_RG_RGTC2, 16),
+	84: compressedFormat(COMPRESSED_SIGNED_RG_RGTC2, 16),
+	87: {gl.RGBA8, gl.BGRA, gl.UNSIGNED_BYTE, 0, 4},
+	91: {gl.SRGB8_ALPHA8, gl.BGRA, gl.UNSIGNED_BYTE, 0, 4},
+	95: compressedFormat(COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT, 16),
+	96: compressedFormat(COMPRESSED_RGB_BPTC_SIGNED_FLOAT, 16),
+	98: compressedFormat(COMPRESSED_RGBA_BPTC_UNORM, 16),
+	99: compressedFormat(COMPRESSED_SRGB_ALPHA_BPTC_UNORM, 16),
+}
+
+// ddsPixelFormat maps a legacy DDS_PIXELFORMAT to a gl format.
+func ddsPixelFormat(pf []byte) (textureFormat, error) {
+	order := binary.LittleEndian
+	flags := order.Uint32(pf[4:])
+	fourCC := string(pf[8:12])
+	bits := order.Uint32(pf[12:])
+	red := order.Uint32(pf[16:])
+	if flags&ddsPixelFormatFourCC != 0 {
+		format, ok := fourCCFormats[fourCC]
+		if !ok {
+			return format, fmt.Errorf("dds: unsupported fourCC %q", fourCC)
+		}
+		if fourCC == "DXT1" && flags&ddsPixelFormatAlpha != 0 {
+			format.InternalFormat = COMPRESSED_RGBA_S3TC_DXT1_EXT
+		}
+		return format, nil
+	}
+	switch {
+	case flags&ddsPixelFormatRGB != 0 && bits == 32 && red == 0xff0000:
+		return textureFormat{gl.RGBA8, gl.BGRA, gl.UNSIGNED_BYTE, 0, 4}, nil
+	case flags&ddsPixelFormatRGB != 0 && bits == 32 && red == 0xff:
+		return textureFormat{gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE, 0, 4}, nil
+	case flags&ddsPixelFormatRGB != 0 && bits == 24 && red == 0xff0000:
+		return textureFormat{gl.RGB8, gl.BGR, gl.UNSIGNED_BYTE, 0, 3}, nil
+	case flags&ddsPixelFormatRGB != 0 && bits == 24 && red == 0xff:
+		return textureFormat{gl.RGB8, gl.RGB, gl.UNSIGNED_BYTE, 0, 3}, nil
+	case flags&ddsPixelFormatLuminance != 0 && bits == 8:
+		return textureFormat{gl.R8, gl.RED, gl.UNSIGNED_BYTE, 0, 1}, nil
+	}
+	return textureFormat{}, fmt.Errorf("dds: unsupported pixel format (flags %#x, %d bits)", flags, bits)
+}
+
+// ParseDDS reads a DirectDraw Surface, including the DX10 header extension
+// used for arrays and BC6H/BC7 formats.
+func ParseDDS(data []byte) (*TextureFile, error) {
+	if len(data) < 128 {
+		return nil, fmt.Errorf("dds: header truncated")
+	}
+	order := binary.LittleEndian
+	header := data[4:128]
+	file := &TextureFile{
+		Width:     int(order.Uint32(header[12:])),
+		Height:    int(order.Uint32(header[8:])),
+		Depth:     1,
+		Faces:     1,
+		Alignment: 1,
+	}
+	caps2 := order.Uint32(header[108:])
+	if caps2&ddsVolume != 0 {
+		file.Depth = int(order.Uint32(header[20:]))
+	}
+	if caps2&ddsCubeMap != 0 {
+		file.Faces = 6
+	}
+	levels := int(order.Uint32(header[24:]))
+	if levels == 0 {
+		levels = 1
+	}
+	offset := 128
+	var format textureFormat
+	if string(header[80:84]) == "DX10" {
+		dx10, err := byteSlice(data, 128, 20)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		format, ok = dxgiFormats[order.Uint32(dx10)]
+		if !ok {
+			return nil, fmt.Errorf("dds: unsupported DXGI format %d", order.Uint32(dx10))
+		}
+		if order.Uint32(dx10[4:]) == dx10Texture3D {
+			file.Depth = int(order.Uint32(header[20:]))
+		} else {
+			file.Depth = 1
+		}
+		if order.Uint32(dx10[8:])&dx10CubeMap != 0 {
+			file.Faces = 6
+		}
+		if layers := int(order.Uint32(dx10[12:])); layers > 1 {
+			file.ArrayLayers = layers
+		}
+		offset += 20
+	} else {
+		var err error
+		format, err = ddsPixelFormat(header[72:104])
+		if err != nil {
+			return nil, err
+		}
+	}
+	file.InternalFormat, file.Format, file.Type = format.InternalFormat, format.Format, format.Type
+	if err := file.checkHeader(levels, len(data)); err != nil {
+		return nil, err
+	}
+	chain := 0
+	for i := 0; i < levels; i++ {
+		level := file.level(i)
+		file.Levels = append(file.Levels, level)
+		chain += format.imageSize(level.Width, level.Height, level.Depth)
+	}
+	if chain == 0 {
+		return nil, fmt.Errorf("dds: invalid image size")
+	}
+	if chain > (len(data)-offset)/file.images() {
+		return nil, fmt.Errorf("dds: data truncated")
+	}
+	// DDS stores each layer and face with its full mip chain in turn.
+	for j := 0; j < file.images(); j++ {
+		for i := range file.Levels {
+			level := &file.Levels[i]
+			size := format.imageSize(level.Width, level.Height, level.Depth)
+			image, err := byteSlice(data, offset, size)
+			if err != nil {
+				return nil, err
+			}
+			level.Images = append(level.Images, image)
+			offset += size
+		}
+	}
+	return file, nil
+}
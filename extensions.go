@@ -0,0 +1,81 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"strings"
+)
+
+// contextInfo is the version and extension list of the context behind a
+// backend, read once since the extension list is long and never changes.
+type contextInfo struct {
+	backend      GLBackend
+	major, minor int
+	extensions   map[string]bool
+
+	maxAnisotropy        gl.Float
+	maxAnisotropyQueried bool
+}
+
+var currentInfo *contextInfo
+
+func queryContext() *contextInfo {
+	if currentInfo != nil && currentInfo.backend == Backend {
+		return currentInfo
+	}
+	info := &contextInfo{backend: Backend, extensions: make(map[string]bool)}
+	var major, minor gl.Int
+	Backend.GetIntegerv(gl.MAJOR_VERSION, &major)
+	Backend.GetIntegerv(gl.MINOR_VERSION, &minor)
+	if major > 0 {
+		info.major, info.minor = int(major), int(minor)
+		var count gl.Int
+		Backend.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+		for i := 0; i < int(count); i++ {
+			info.extensions[Backend.GetStringi(gl.EXTENSIONS, gl.Uint(i))] = true
+		}
+	} else {
+		// Legacy contexts reject the indexed queries; clear the error and
+		// fall back to the version and extension strings.
+		Backend.GetError()
+		fmt.Sscanf(Backend.GetString(gl.VERSION), "%d.%d", &info.major, &info.minor)
+		for _, name := range strings.Fields(Backend.GetString(gl.EXTENSIONS)) {
+			info.extensions[name] = true
+		}
+	}
+	currentInfo = info
+	return info
+}
+
+// ForgetContext drops the cached version and extensions, for when the
+// context is recreated behind the same backend.
+func ForgetContext() {
+	currentInfo = nil
+}
+
+// HasExtension reports whether the current context advertises the named
+// extension.
+func HasExtension(name string) bool {
+	return queryContext().extensions[name]
+}
+
+// VersionAtLeast reports whether the current context is at least the given
+// GL version.
+func VersionAtLeast(major, minor int) bool {
+	info := queryContext()
+	return info.major > major || info.major == major && info.minor >= minor
+}
+
+// MaxAnisotropy is the largest anisotropic filtering level the current
+// context supports, or 1 when it has no anisotropic filtering.
+func MaxAnisotropy() float32 {
+	info := queryContext()
+	if !info.maxAnisotropyQueried {
+		info.maxAnisotropy = 1
+		if info.extensions["GL_EXT_texture_filter_anisotropic"] || info.extensions["GL_ARB_texture_filter_anisotropic"] || VersionAtLeast(4, 6) {
+			Backend.GetFloatv(MAX_TEXTURE_MAX_ANISOTROPY_EXT, &info.maxAnisotropy)
+		}
+		info.maxAnisotropyQueried = true
+	}
+	return float32(info.maxAnisotropy)
+}
@@ -0,0 +1,398 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+)
+
+type Attachment struct {
+	Point          gl.Enum
+	InternalFormat gl.Enum
+	Renderbuffer   bool
+	Texture        gl.Texture
+	Buffer         gl.Renderbuffer
+}
+
+func ColorTexture(index int, internalFormat gl.Enum) Attachment {
+	return Attachment{Point: gl.COLOR_ATTACHMENT0 + gl.Enum(index), InternalFormat: internalFormat}
+}
+
+func ColorRenderbuffer(index int, internalFormat gl.Enum) Attachment {
+	return Attachment{Point: gl.COLOR_ATTACHMENT0 + gl.Enum(index), InternalFormat: internalFormat, Renderbuffer: true}
+}
+
+func DepthTexture(internalFormat gl.Enum) Attachment {
+	return Attachment{Point: gl.DEPTH_ATTACHMENT, InternalFormat: internalFormat}
+}
+
+func DepthRenderbuffer(internalFormat gl.Enum) Attachment {
+	return Attachment{Point: gl.DEPTH_ATTACHMENT, InternalFormat: internalFormat, Renderbuffer: true}
+}
+
+func DepthStencilRenderbuffer(internalFormat gl.Enum) Attachment {
+	return Attachment{Point: gl.DEPTH_STENCIL_ATTACHMENT, InternalFormat: internalFormat, Renderbuffer: true}
+}
+
+func (a *Attachment) isColor() bool {
+	return a.Point >= gl.COLOR_ATTACHMENT0 && a.Point < gl.COLOR_ATTACHMENT0+32
+}
+
+func (a *Attachment) bufferMask() gl.Bitfield {
+	switch a.Point {
+	case gl.DEPTH_ATTACHMENT:
+		return gl.DEPTH_BUFFER_BIT
+	case gl.STENCIL_ATTACHMENT:
+		return gl.STENCIL_BUFFER_BIT
+	case gl.DEPTH_STENCIL_ATTACHMENT:
+		return gl.DEPTH_BUFFER_BIT | gl.STENCIL_BUFFER_BIT
+	}
+	return gl.COLOR_BUFFER_BIT
+}
+
+// storageFormat picks an external format and type TexImage2D accepts for
+// an internal format when no pixels are given. Integer formats need the
+// matching _INTEGER format and a type of the same signedness.
+func storageFormat(internalFormat gl.Enum) (gl.Enum, gl.Enum, error) {
+	switch internalFormat {
+	case gl.DEPTH_COMPONENT16, gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT32:
+		return gl.DEPTH_COMPONENT, gl.UNSIGNED_INT, nil
+	case gl.DEPTH_COMPONENT32F:
+		return gl.DEPTH_COMPONENT, gl.FLOAT, nil
+	case gl.DEPTH24_STENCIL8:
+		return gl.DEPTH_STENCIL, gl.UNSIGNED_INT_24_8, nil
+	case gl.DEPTH32F_STENCIL8:
+		return gl.DEPTH_STENCIL, gl.FLOAT_32_UNSIGNED_INT_24_8_REV, nil
+	case gl.R8:
+		return gl.RED, gl.UNSIGNED_BYTE, nil
+	case gl.R16:
+		return gl.RED, gl.UNSIGNED_SHORT, nil
+	case gl.R16F, gl.R32F:
+		return gl.RED, gl.FLOAT, nil
+	case gl.RG8:
+		return gl.RG, gl.UNSIGNED_BYTE, nil
+	case gl.RG16:
+		return gl.RG, gl.UNSIGNED_SHORT, nil
+	case gl.RG16F, gl.RG32F:
+		return gl.RG, gl.FLOAT, nil
+	case gl.RGB8, gl.SRGB8:
+		return gl.RGB, gl.UNSIGNED_BYTE, nil
+	case gl.RGB16F, gl.RGB32F, gl.R11F_G11F_B10F:
+		return gl.RGB, gl.FLOAT, nil
+	case gl.RGBA8, gl.SRGB8_ALPHA8:
+		return gl.RGBA, gl.UNSIGNED_BYTE, nil
+	case gl.RGBA16:
+		return gl.RGBA, gl.UNSIGNED_SHORT, nil
+	case gl.RGB10_A2:
+		return gl.RGBA, gl.UNSIGNED_INT_2_10_10_10_REV, nil
+	case gl.RGBA16F, gl.RGBA32F:
+		return gl.RGBA, gl.FLOAT, nil
+	case gl.R8I:
+		return gl.RED_INTEGER, gl.BYTE, nil
+	case gl.R8UI:
+		return gl.RED_INTEGER, gl.UNSIGNED_BYTE, nil
+	case gl.R16I:
+		return gl.RED_INTEGER, gl.SHORT, nil
+	case gl.R16UI:
+		return gl.RED_INTEGER, gl.UNSIGNED_SHORT, nil
+	case gl.R32I:
+		return gl.RED_INTEGER, gl.INT, nil
+	case gl.R32UI:
+		return gl.RED_INTEGER, gl.UNSIGNED_INT, nil
+	case gl.RG8I:
+		return gl.RG_INTEGER, gl.BYTE, nil
+	case gl.RG8UI:
+		return gl.RG_INTEGER, gl.UNSIGNED_BYTE, nil
+	case gl.RG16I:
+		return gl.RG_INTEGER, gl.SHORT, nil
+	case gl.RG16UI:
+		return gl.RG_INTEGER, gl.UNSIGNED_SHORT, nil
+	case gl.RG32I:
+		return gl.RG_INTEGER, gl.INT, nil
+	case gl.RG32UI:
+		return gl.RG_INTEGER, gl.UNSIGNED_INT, nil
+	case gl.RGBA8I:
+		return gl.RGBA_INTEGER, gl.BYTE, nil
+	case gl.RGBA8UI:
+		return gl.RGBA_INTEGER, gl.UNSIGNED_BYTE, nil
+	case gl.RGBA16I:
+		return gl.RGBA_INTEGER, gl.SHORT, nil
+	case gl.RGBA16UI:
+		return gl.RGBA_INTEGER, gl.UNSIGNED_SHORT, nil
+	case gl.RGBA32I:
+		return gl.RGBA_INTEGER, gl.INT, nil
+	case gl.RGBA32UI:
+		return gl.RGBA_INTEGER, gl.UNSIGNED_INT, nil
+	}
+	return 0, 0, fmt.Errorf("framebuffer: no texture storage for internal format 0x%X", internalFormat)
+}
+
+func integerFormat(format gl.Enum) bool {
+	switch format {
+	case gl.RED_INTEGER, gl.RG_INTEGER, gl.RGB_INTEGER, gl.RGBA_INTEGER:
+		return true
+	}
+	return false
+}
+
+var framebufferStatus = map[gl.Enum]string{
+	gl.FRAMEBUFFER_UNDEFINED:                     "the default framebuffer does not exist",
+	gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT:         "an attachment is incomplete or has a zero size",
+	gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT: "no images are attached",
+	gl.FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:        "a draw buffer names an empty attachment",
+	gl.FRAMEBUFFER_INCOMPLETE_READ_BUFFER:        "the read buffer names an empty attachment",
+	gl.FRAMEBUFFER_UNSUPPORTED:                   "the combination of internal formats is not supported",
+	gl.FRAMEBUFFER_INCOMPLETE_MULTISAMPLE:        "attachments have different sample counts",
+	gl.FRAMEBUFFER_INCOMPLETE_LAYER_TARGETS:      "attachments are not all layered",
+}
+
+type FramebufferError struct {
+	Framebuffer gl.Framebuffer
+	Status      gl.Enum
+}
+
+func (e *FramebufferError) Error() string {
+	reason, ok := framebufferStatus[e.Status]
+	if !ok {
+		reason = fmt.Sprintf("status 0x%X", e.Status)
+	}
+	return fmt.Sprintf("framebuffer %d incomplete: %s", e.Framebuffer, reason)
+}
+
+// Framebuffer renders into textures or renderbuffers. Color attachments
+// are drawn in the order given, so fragment output n goes to the n-th color
+// attachment. A non-zero sample count makes every attachment multisampled;
+// use Resolve to copy the result into a single sampled framebuffer.
+type Framebuffer struct {
+	Id          gl.Framebuffer
+	Width       int
+	Height      int
+	Samples     int
+	Attachments []Attachment
+}
+
+// NewFramebuffer copies attachments, so the caller's slice is never
+// written to.
+func NewFramebuffer(width, height, samples int, attachments ...Attachment) (*Framebuffer, error) {
+	fb := &Framebuffer{GenFramebuffer(), width, height, samples, append([]Attachment(nil), attachments...)}
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, fb.Id)
+	for i := range fb.Attachments {
+		a := &fb.Attachments[i]
+		if a.Renderbuffer {
+			a.Buffer = GenRenderbuffer()
+		} else {
+			a.Texture = GenTexture()
+		}
+		if err := fb.allocate(a); err != nil {
+			Backend.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			fb.Delete()
+			return nil, err
+		}
+		if a.Renderbuffer {
+			Backend.FramebufferRenderbuffer(gl.FRAMEBUFFER, a.Point, gl.RENDERBUFFER, a.Buffer)
+		} else {
+			Backend.FramebufferTexture2D(gl.FRAMEBUFFER, a.Point, fb.textureTarget(), a.Texture, 0)
+		}
+	}
+	colors := fb.colorPoints()
+	if len(colors) > 0 {
+		Backend.DrawBuffers(gl.Sizei(len(colors)), &colors[0])
+		Backend.ReadBuffer(colors[0])
+	} else {
+		Backend.DrawBuffer(gl.NONE)
+		Backend.ReadBuffer(gl.NONE)
+	}
+	err := fb.Check()
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if err != nil {
+		fb.Delete()
+		return nil, err
+	}
+	return fb, nil
+}
+
+func (fb *Framebuffer) textureTarget() gl.Enum {
+	if fb.Samples > 0 {
+		return gl.TEXTURE_2D_MULTISAMPLE
+	}
+	return gl.TEXTURE_2D
+}
+
+func (fb *Framebuffer) allocate(a *Attachment) error {
+	width, height := gl.Sizei(fb.Width), gl.Sizei(fb.Height)
+	if a.Renderbuffer {
+		Backend.BindRenderbuffer(gl.RENDERBUFFER, a.Buffer)
+		if fb.Samples > 0 {
+			Backend.RenderbufferStorageMultisample(gl.RENDERBUFFER, gl.Sizei(fb.Samples), a.InternalFormat, width, height)
+		} else {
+			Backend.RenderbufferStorage(gl.RENDERBUFFER, a.InternalFormat, width, height)
+		}
+		Backend.BindRenderbuffer(gl.RENDERBUFFER, 0)
+		return nil
+	}
+	target := fb.textureTarget()
+	if fb.Samples > 0 {
+		Backend.BindTexture(target, a.Texture)
+		Backend.TexImage2DMultisample(target, gl.Sizei(fb.Samples), gl.Int(a.InternalFormat), width, height, gl.TRUE)
+		Backend.BindTexture(target, 0)
+		return nil
+	}
+	format, xtype, err := storageFormat(a.InternalFormat)
+	if err != nil {
+		return err
+	}
+	// Integer textures are incomplete under linear filtering, and sample as
+	// zero.
+	options := DefaultTextureOptions
+	if integerFormat(format) {
+		options.MinFilter, options.MagFilter = gl.NEAREST, gl.NEAREST
+	}
+	Backend.BindTexture(target, a.Texture)
+	options.SetParameters(target)
+	Backend.TexImage2D(target, 0, gl.Int(a.InternalFormat), width, height, 0, format, xtype, nil)
+	Backend.BindTexture(target, 0)
+	return nil
+}
+
+func (fb *Framebuffer) colorPoints() []gl.Enum {
+	var points []gl.Enum
+	for i := range fb.Attachments {
+		if fb.Attachments[i].isColor() {
+			points = append(points, fb.Attachments[i].Point)
+		}
+	}
+	return points
+}
+
+func (fb *Framebuffer) bufferMask() gl.Bitfield {
+	var mask gl.Bitfield
+	for i := range fb.Attachments {
+		mask |= fb.Attachments[i].bufferMask()
+	}
+	return mask
+}
+
+// Check reports why the bound framebuffer is incomplete, if it is.
+func (fb *Framebuffer) Check() error {
+	status := Backend.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		return &FramebufferError{fb.Id, status}
+	}
+	return nil
+}
+
+// Resize reallocates every attachment, typically from Reshape. The
+// attachment textures keep their ids.
+func (fb *Framebuffer) Resize(width, height int) error {
+	if width == fb.Width && height == fb.Height {
+		return nil
+	}
+	fb.Width, fb.Height = width, height
+	for i := range fb.Attachments {
+		if err := fb.allocate(&fb.Attachments[i]); err != nil {
+			return err
+		}
+	}
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, fb.Id)
+	err := fb.Check()
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	return err
+}
+
+// Bind directs drawing into the framebuffer and sets the viewport to cover
+// it.
+func (fb *Framebuffer) Bind() {
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, fb.Id)
+	Backend.Viewport(0, 0, gl.Sizei(fb.Width), gl.Sizei(fb.Height))
+}
+
+func BindDefaultFramebuffer(width, height int) {
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	Backend.Viewport(0, 0, gl.Sizei(width), gl.Sizei(height))
+}
+
+// ColorTexture returns the texture of the index-th color attachment.
+func (fb *Framebuffer) ColorTexture(index int) gl.Texture {
+	for i := range fb.Attachments {
+		a := &fb.Attachments[i]
+		if a.isColor() {
+			if index == 0 {
+				return a.Texture
+			}
+			index--
+		}
+	}
+	return 0
+}
+
+func (fb *Framebuffer) DepthTexture() gl.Texture {
+	for i := range fb.Attachments {
+		if fb.Attachments[i].bufferMask()&gl.DEPTH_BUFFER_BIT != 0 {
+			return fb.Attachments[i].Texture
+		}
+	}
+	return 0
+}
+
+func (fb *Framebuffer) blit(width, height int, mask gl.Bitfield, filter gl.Enum) {
+	Backend.BlitFramebuffer(
+		0, 0, gl.Int(fb.Width), gl.Int(fb.Height),
+		0, 0, gl.Int(width), gl.Int(height),
+		mask, filter,
+	)
+}
+
+// Blit copies the buffers in mask to dst, scaling to fit. A nil dst is the
+// default framebuffer, taken to be the same size.
+func (fb *Framebuffer) Blit(dst *Framebuffer, mask gl.Bitfield, filter gl.Enum) {
+	width, height := fb.Width, fb.Height
+	var id gl.Framebuffer
+	if dst != nil {
+		id, width, height = dst.Id, dst.Width, dst.Height
+	}
+	Backend.BindFramebuffer(gl.READ_FRAMEBUFFER, fb.Id)
+	Backend.BindFramebuffer(gl.DRAW_FRAMEBUFFER, id)
+	fb.blit(width, height, mask, filter)
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+// Resolve copies a multisampled framebuffer into dst, which must be the
+// same size. Each color attachment is copied to the matching attachment of
+// dst, as are depth and stencil when both have them. A nil dst is the
+// default framebuffer, which receives the first color attachment.
+func (fb *Framebuffer) Resolve(dst *Framebuffer) {
+	Backend.BindFramebuffer(gl.READ_FRAMEBUFFER, fb.Id)
+	if dst == nil {
+		Backend.BindFramebuffer(gl.DRAW_FRAMEBUFFER, 0)
+		fb.blit(fb.Width, fb.Height, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+		Backend.BindFramebuffer(gl.FRAMEBUFFER, 0)
+		return
+	}
+	Backend.BindFramebuffer(gl.DRAW_FRAMEBUFFER, dst.Id)
+	src, dstColors := fb.colorPoints(), dst.colorPoints()
+	for i := 0; i < len(src) && i < len(dstColors); i++ {
+		Backend.ReadBuffer(src[i])
+		Backend.DrawBuffers(1, &dstColors[i])
+		fb.blit(dst.Width, dst.Height, gl.COLOR_BUFFER_BIT, gl.NEAREST)
+	}
+	if mask := fb.bufferMask() & dst.bufferMask() &^ gl.COLOR_BUFFER_BIT; mask != 0 {
+		fb.blit(dst.Width, dst.Height, mask, gl.NEAREST)
+	}
+	if len(src) > 0 {
+		Backend.ReadBuffer(src[0])
+	}
+	if len(dstColors) > 0 {
+		Backend.DrawBuffers(gl.Sizei(len(dstColors)), &dstColors[0])
+	}
+	Backend.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+func (fb *Framebuffer) Delete() {
+	for i := range fb.Attachments {
+		a := &fb.Attachments[i]
+		DeleteTexture(a.Texture)
+		DeleteRenderbuffer(a.Buffer)
+		a.Texture, a.Buffer = 0, 0
+	}
+	DeleteFramebuffer(fb.Id)
+	fb.Id = 0
+}
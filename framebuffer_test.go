@@ -0,0 +1,205 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"testing"
+)
+
+func TestStorageFormat(t *testing.T) {
+	tests := []struct {
+		internalFormat, format, xtype gl.Enum
+	}{
+		{gl.DEPTH_COMPONENT24, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT},
+		{gl.DEPTH_COMPONENT32, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT},
+		{gl.DEPTH_COMPONENT32F, gl.DEPTH_COMPONENT, gl.FLOAT},
+		{gl.DEPTH24_STENCIL8, gl.DEPTH_STENCIL, gl.UNSIGNED_INT_24_8},
+		{gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE},
+		{gl.RGBA16F, gl.RGBA, gl.FLOAT},
+		{gl.R8UI, gl.RED_INTEGER, gl.UNSIGNED_BYTE},
+		{gl.R32I, gl.RED_INTEGER, gl.INT},
+		{gl.RG16I, gl.RG_INTEGER, gl.SHORT},
+		{gl.RG32UI, gl.RG_INTEGER, gl.UNSIGNED_INT},
+		{gl.RGBA8I, gl.RGBA_INTEGER, gl.BYTE},
+		{gl.RGBA16UI, gl.RGBA_INTEGER, gl.UNSIGNED_SHORT},
+	}
+	for _, test := range tests {
+		format, xtype, err := storageFormat(test.internalFormat)
+		if err != nil || format != test.format || xtype != test.xtype {
+			t.Errorf("0x%X: format 0x%X type 0x%X error %v, want 0x%X 0x%X", test.internalFormat, format, xtype, err, test.format, test.xtype)
+		}
+	}
+	if _, _, err := storageFormat(COMPRESSED_RGBA_S3TC_DXT5_EXT); err == nil {
+		t.Error("compressed format given storage")
+	}
+}
+
+func TestNewFramebufferUnknownFormat(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.FramebufferStatus = gl.FRAMEBUFFER_COMPLETE
+	fb, err := NewFramebuffer(4, 4, 0, ColorTexture(0, gl.RGBA8), ColorTexture(1, COMPRESSED_RGBA_S3TC_DXT5_EXT), DepthRenderbuffer(gl.DEPTH_COMPONENT24))
+	if err == nil || fb != nil {
+		t.Fatalf("framebuffer %v error %v", fb, err)
+	}
+	if len(r.Live) != 0 {
+		t.Errorf("leaked %v", r.Live)
+	}
+	if r.BoundFramebuffers[gl.FRAMEBUFFER] != 0 {
+		t.Error("framebuffer left bound")
+	}
+}
+
+func TestNewFramebufferCopiesAttachments(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.FramebufferStatus = gl.FRAMEBUFFER_COMPLETE
+	attachments := []Attachment{ColorTexture(0, gl.RGBA8), DepthRenderbuffer(gl.DEPTH_COMPONENT24)}
+	fb, err := NewFramebuffer(4, 4, 0, attachments...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachments[0].Texture != 0 || attachments[1].Buffer != 0 {
+		t.Errorf("caller's attachments written: %+v", attachments)
+	}
+	if fb.ColorTexture(0) == 0 || fb.Attachments[1].Buffer == 0 {
+		t.Errorf("framebuffer attachments %+v", fb.Attachments)
+	}
+	fb.Delete()
+	if len(r.Live) != 0 {
+		t.Errorf("leaked %v", r.Live)
+	}
+}
+
+func TestFramebufferResize(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	fb, err := NewFramebuffer(4, 4, 0, ColorTexture(0, gl.RGBA8), DepthRenderbuffer(gl.DEPTH_COMPONENT24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Reset()
+	if err := fb.Resize(4, 4); err != nil || len(r.Calls) != 0 {
+		t.Errorf("resize to the same size: error %v calls %v", err, r.Calls)
+	}
+	if err := fb.Resize(8, 6); err != nil {
+		t.Fatal(err)
+	}
+	checkCalls(t, "Resize", r.Calls, []Call{
+		call("BindTexture", gl.Enum(gl.TEXTURE_2D), gl.Texture(2)),
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(gl.TEXTURE_MIN_FILTER), gl.Int(gl.LINEAR)),
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(gl.TEXTURE_MAG_FILTER), gl.Int(gl.LINEAR)),
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(gl.TEXTURE_WRAP_S), gl.Int(gl.CLAMP_TO_EDGE)),
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(gl.TEXTURE_WRAP_T), gl.Int(gl.CLAMP_TO_EDGE)),
+		call("TexImage2D", gl.Enum(gl.TEXTURE_2D), gl.Int(0), gl.Int(gl.RGBA8), gl.Sizei(8), gl.Sizei(6), gl.Int(0), gl.Enum(gl.RGBA), gl.Enum(gl.UNSIGNED_BYTE), gl.Pointer(nil)),
+		call("BindTexture", gl.Enum(gl.TEXTURE_2D), gl.Texture(0)),
+		call("BindRenderbuffer", gl.Enum(gl.RENDERBUFFER), gl.Renderbuffer(3)),
+		call("RenderbufferStorage", gl.Enum(gl.RENDERBUFFER), gl.Enum(gl.DEPTH_COMPONENT24), gl.Sizei(8), gl.Sizei(6)),
+		call("BindRenderbuffer", gl.Enum(gl.RENDERBUFFER), gl.Renderbuffer(0)),
+		call("BindFramebuffer", gl.Enum(gl.FRAMEBUFFER), gl.Framebuffer(1)),
+		call("CheckFramebufferStatus", gl.Enum(gl.FRAMEBUFFER)),
+		call("BindFramebuffer", gl.Enum(gl.FRAMEBUFFER), gl.Framebuffer(0)),
+	})
+	if fb.Width != 8 || fb.Height != 6 || fb.ColorTexture(0) != 2 {
+		t.Errorf("resized to %+v", fb)
+	}
+	r.FramebufferStatus = gl.FRAMEBUFFER_UNSUPPORTED
+	if err := fb.Resize(16, 16); err == nil {
+		t.Error("incomplete framebuffer resized without error")
+	}
+}
+
+func TestFramebufferIntegerFiltering(t *testing.T) {
+	tests := []struct {
+		internalFormat gl.Enum
+		filter         gl.Enum
+	}{
+		{gl.RGBA8, gl.LINEAR},
+		{gl.RGBA16F, gl.LINEAR},
+		{gl.R32UI, gl.NEAREST},
+		{gl.RGBA32I, gl.NEAREST},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		previous := SetBackend(r)
+		_, err := NewFramebuffer(4, 4, 0, ColorTexture(0, test.internalFormat))
+		SetBackend(previous)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range r.Calls {
+			if c.Name != "TexParameteri" {
+				continue
+			}
+			pname := c.Args[1].(gl.Enum)
+			if (pname == gl.TEXTURE_MIN_FILTER || pname == gl.TEXTURE_MAG_FILTER) && c.Args[2] != gl.Int(test.filter) {
+				t.Errorf("0x%X: filter 0x%X set to %v, want 0x%X", test.internalFormat, pname, c.Args[2], test.filter)
+			}
+		}
+	}
+}
+
+func TestFramebufferResolve(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	src, err := NewFramebuffer(4, 4, 4, ColorRenderbuffer(0, gl.RGBA8), ColorRenderbuffer(1, gl.RGBA16F), DepthRenderbuffer(gl.DEPTH_COMPONENT24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := NewFramebuffer(4, 4, 0, ColorTexture(0, gl.RGBA8), ColorTexture(1, gl.RGBA16F), DepthTexture(gl.DEPTH_COMPONENT24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c0, c1 := gl.Enum(gl.COLOR_ATTACHMENT0), gl.Enum(gl.COLOR_ATTACHMENT0+1)
+	blit := func(mask gl.Bitfield) Call {
+		return call("BlitFramebuffer", gl.Int(0), gl.Int(0), gl.Int(4), gl.Int(4), gl.Int(0), gl.Int(0), gl.Int(4), gl.Int(4), mask, gl.Enum(gl.NEAREST))
+	}
+	r.Reset()
+	src.Resolve(dst)
+	checkCalls(t, "Resolve", r.Calls, []Call{
+		call("BindFramebuffer", gl.Enum(gl.READ_FRAMEBUFFER), src.Id),
+		call("BindFramebuffer", gl.Enum(gl.DRAW_FRAMEBUFFER), dst.Id),
+		call("ReadBuffer", c0),
+		call("DrawBuffers", gl.Sizei(1), []gl.Enum{c0}),
+		blit(gl.COLOR_BUFFER_BIT),
+		call("ReadBuffer", c1),
+		call("DrawBuffers", gl.Sizei(1), []gl.Enum{c1}),
+		blit(gl.COLOR_BUFFER_BIT),
+		blit(gl.DEPTH_BUFFER_BIT),
+		call("ReadBuffer", c0),
+		call("DrawBuffers", gl.Sizei(2), []gl.Enum{c0, c1}),
+		call("BindFramebuffer", gl.Enum(gl.FRAMEBUFFER), gl.Framebuffer(0)),
+	})
+	r.Reset()
+	src.Resolve(nil)
+	checkCalls(t, "Resolve to default", r.Calls, []Call{
+		call("BindFramebuffer", gl.Enum(gl.READ_FRAMEBUFFER), src.Id),
+		call("BindFramebuffer", gl.Enum(gl.DRAW_FRAMEBUFFER), gl.Framebuffer(0)),
+		blit(gl.COLOR_BUFFER_BIT),
+		call("BindFramebuffer", gl.Enum(gl.FRAMEBUFFER), gl.Framebuffer(0)),
+	})
+}
+
+func TestFramebufferError(t *testing.T) {
+	tests := []struct {
+		status gl.Enum
+		want   string
+	}{
+		{gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT, "framebuffer 1 incomplete: no images are attached"},
+		{gl.FRAMEBUFFER_UNSUPPORTED, "framebuffer 1 incomplete: the combination of internal formats is not supported"},
+		{0x1234, "framebuffer 1 incomplete: status 0x1234"},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		previous := SetBackend(r)
+		r.FramebufferStatus = test.status
+		fb, err := NewFramebuffer(4, 4, 0, ColorTexture(0, gl.RGBA8))
+		SetBackend(previous)
+		fbErr, ok := err.(*FramebufferError)
+		if fb != nil || !ok || fbErr.Status != test.status || err.Error() != test.want {
+			t.Errorf("0x%X: framebuffer %v error %v, want %q", test.status, fb, err, test.want)
+		}
+		if len(r.Live) != 0 {
+			t.Errorf("0x%X: leaked %v", test.status, r.Live)
+		}
+	}
+}
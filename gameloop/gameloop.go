@@ -28,6 +28,13 @@ type WindowDelegate interface {
 	NeedsRender() bool
 }
 
+// FrameCapturer is implemented by delegates which read back drawn frames,
+// for example to record them. CaptureFrame runs after Draw and before the
+// buffers are swapped.
+type FrameCapturer interface {
+	CaptureFrame(window *glfw.Window)
+}
+
 type WindowDelegator struct {
 	Delegate WindowDelegate
 }
@@ -110,7 +117,16 @@ func bindEvents(window *glfw.Window, delegate WindowDelegate) {
 	window.SetCloseCallback(delegate.OnClose)
 }
 
+var legacyContext bool
+
+// LegacyContext reports whether the window was created without requesting a
+// 3.2 core context, in which case shaders need ShaderOptions.Legacy.
+func LegacyContext() bool {
+	return legacyContext
+}
+
 func CreateWindow(width, height int, name string, fullscreen bool, delegate WindowDelegate, legacy bool) error {
+	legacyContext = legacy
 	if !glfw.Init() {
 		return errors.New("Failed to initialize GLFW")
 	}
@@ -172,6 +188,9 @@ func CreateWindow(width, height int, name string, fullscreen bool, delegate Wind
 		doSimulation()
 		if delegate.NeedsRender() {
 			delegate.Draw(window)
+			if capturer, ok := delegate.(FrameCapturer); ok {
+				capturer.CaptureFrame(window)
+			}
 		}
 		window.SwapBuffers()
 		if delegate.IsIdle() {
@@ -0,0 +1,215 @@
+package render
+
+import (
+	"encoding/binary"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+	"image/draw"
+	"unsafe"
+)
+
+// ARB_texture_swizzle is core in 3.3 and not part of gl32.
+const (
+	TEXTURE_SWIZZLE_R = 0x8E42
+	TEXTURE_SWIZZLE_G = 0x8E43
+	TEXTURE_SWIZZLE_B = 0x8E44
+	TEXTURE_SWIZZLE_A = 0x8E45
+)
+
+var (
+	graySwizzle  = [4]gl.Enum{gl.RED, gl.RED, gl.RED, gl.ONE}
+	alphaSwizzle = [4]gl.Enum{gl.ONE, gl.ONE, gl.ONE, gl.RED}
+)
+
+type PixelData struct {
+	Width          int
+	Height         int
+	InternalFormat gl.Enum
+	Format         gl.Enum
+	Type           gl.Enum
+	Alignment      int
+	Pixels         []byte
+	// Swizzle maps the stored channels to RGBA when sampled, the zero value
+	// leaving them as they are.
+	Swizzle [4]gl.Enum
+}
+
+func (p *PixelData) Pointer() gl.Pointer {
+	if len(p.Pixels) == 0 {
+		return nil
+	}
+	return gl.Pointer(&p.Pixels[0])
+}
+
+// ImagePixels converts any image into tightly packed rows ready for upload.
+// Gray and 16 bit images keep their channel count and depth, everything else
+// is converted to 8 bit RGBA. Gray and alpha images are stored in the red
+// channel with a Swizzle that reads them back as (R,R,R,1) and (1,1,1,R).
+func ImagePixels(img image.Image) *PixelData {
+	bounds := img.Bounds()
+	p := &PixelData{Width: bounds.Dx(), Height: bounds.Dy(), Alignment: 4}
+	switch img := img.(type) {
+	case *image.Gray:
+		p.Format, p.Type, p.InternalFormat = gl.RED, gl.UNSIGNED_BYTE, gl.R8
+		p.Swizzle = graySwizzle
+		p.Pixels = packRows(img.Pix, img.Stride, img.PixOffset(bounds.Min.X, bounds.Min.Y), p.Width, p.Height, 1)
+	case *image.Alpha:
+		p.Format, p.Type, p.InternalFormat = gl.RED, gl.UNSIGNED_BYTE, gl.R8
+		p.Swizzle = alphaSwizzle
+		p.Pixels = packRows(img.Pix, img.Stride, img.PixOffset(bounds.Min.X, bounds.Min.Y), p.Width, p.Height, 1)
+	case *image.Gray16:
+		p.Format, p.Type, p.InternalFormat = gl.RED, gl.UNSIGNED_SHORT, gl.R16
+		p.Swizzle = graySwizzle
+		p.Pixels = swap16(packRows(img.Pix, img.Stride, img.PixOffset(bounds.Min.X, bounds.Min.Y), p.Width, p.Height, 2))
+	case *image.NRGBA64:
+		p.Format, p.Type, p.InternalFormat = gl.RGBA, gl.UNSIGNED_SHORT, gl.RGBA16
+		p.Pixels = swap16(packRows(img.Pix, img.Stride, img.PixOffset(bounds.Min.X, bounds.Min.Y), p.Width, p.Height, 8))
+	case *image.RGBA64:
+		p.Format, p.Type, p.InternalFormat = gl.RGBA, gl.UNSIGNED_SHORT, gl.RGBA16
+		p.Pixels = swap16(packRows(img.Pix, img.Stride, img.PixOffset(bounds.Min.X, bounds.Min.Y), p.Width, p.Height, 8))
+	case *image.NRGBA:
+		p.Format, p.Type, p.InternalFormat = gl.RGBA, gl.UNSIGNED_BYTE, gl.RGBA8
+		p.Pixels = packRows(img.Pix, img.Stride, img.PixOffset(bounds.Min.X, bounds.Min.Y), p.Width, p.Height, 4)
+	case *image.RGBA:
+		p.Format, p.Type, p.InternalFormat = gl.RGBA, gl.UNSIGNED_BYTE, gl.RGBA8
+		p.Pixels = packRows(img.Pix, img.Stride, img.PixOffset(bounds.Min.X, bounds.Min.Y), p.Width, p.Height, 4)
+	default:
+		nrgba := toNRGBA(img)
+		p.Format, p.Type, p.InternalFormat = gl.RGBA, gl.UNSIGNED_BYTE, gl.RGBA8
+		p.Pixels = nrgba.Pix
+	}
+	if p.Format == gl.RGBA && isOpaque(img) {
+		if p.Type == gl.UNSIGNED_SHORT {
+			p.InternalFormat = gl.RGB16
+		} else {
+			p.InternalFormat = gl.RGB8
+		}
+	}
+	if p.Height > 0 {
+		p.Alignment = rowAlignment(len(p.Pixels) / p.Height)
+	}
+	return p
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+	return nrgba
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface {
+		Opaque() bool
+	}); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+func packRows(pix []byte, stride, offset, width, height, bytesPerPixel int) []byte {
+	rowSize := width * bytesPerPixel
+	if stride == rowSize && offset == 0 && len(pix) == rowSize*height {
+		return pix
+	}
+	packed := make([]byte, rowSize*height)
+	for y := 0; y < height; y++ {
+		copy(packed[y*rowSize:(y+1)*rowSize], pix[offset+y*stride:])
+	}
+	return packed
+}
+
+var nativeBigEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 0
+}()
+
+// nativeOrder is the host's byte order, which gl reads buffer contents in.
+var nativeOrder = func() binary.ByteOrder {
+	if nativeBigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}()
+
+// Go stores 16 bit samples big endian while gl reads them in native order,
+// so they only need swapping on little endian hosts.
+func swap16(pix []byte) []byte {
+	if nativeBigEndian {
+		return pix
+	}
+	swapped := make([]byte, len(pix))
+	for i := 0; i+1 < len(pix); i += 2 {
+		swapped[i], swapped[i+1] = pix[i+1], pix[i]
+	}
+	return swapped
+}
+
+func rowAlignment(rowSize int) int {
+	for _, alignment := range []int{8, 4, 2} {
+		if rowSize%alignment == 0 {
+			return alignment
+		}
+	}
+	return 1
+}
+
+func (p *PixelData) FlipRows() {
+	if p.Height == 0 {
+		return
+	}
+	rowSize := len(p.Pixels) / p.Height
+	flipped := make([]byte, len(p.Pixels))
+	for y := 0; y < p.Height; y++ {
+		copy(flipped[y*rowSize:(y+1)*rowSize], p.Pixels[(p.Height-1-y)*rowSize:])
+	}
+	p.Pixels = flipped
+}
+
+// Expand returns the pixels with their Swizzle applied on the cpu, for
+// contexts that cannot swizzle: gray becomes RGB and alpha becomes RGBA.
+// Pixels without a Swizzle are returned as they are.
+func (p *PixelData) Expand() *PixelData {
+	if p.Swizzle == [4]gl.Enum{} {
+		return p
+	}
+	sampleSize := 1
+	if p.Type == gl.UNSIGNED_SHORT {
+		sampleSize = 2
+	}
+	channels := 4
+	if p.Swizzle[3] == gl.ONE {
+		channels = 3
+	}
+	expanded := *p
+	expanded.Swizzle = [4]gl.Enum{}
+	switch {
+	case channels == 3 && sampleSize == 1:
+		expanded.Format, expanded.InternalFormat = gl.RGB, gl.RGB8
+	case channels == 3:
+		expanded.Format, expanded.InternalFormat = gl.RGB, gl.RGB16
+	case sampleSize == 1:
+		expanded.Format, expanded.InternalFormat = gl.RGBA, gl.RGBA8
+	default:
+		expanded.Format, expanded.InternalFormat = gl.RGBA, gl.RGBA16
+	}
+	expanded.Pixels = make([]byte, len(p.Pixels)*channels)
+	for i := 0; i+sampleSize <= len(p.Pixels); i += sampleSize {
+		out := expanded.Pixels[i*channels:]
+		for c := 0; c < channels; c++ {
+			sample := out[c*sampleSize : (c+1)*sampleSize]
+			switch p.Swizzle[c] {
+			case gl.RED:
+				copy(sample, p.Pixels[i:i+sampleSize])
+			case gl.ONE:
+				for b := range sample {
+					sample[b] = 0xff
+				}
+			}
+		}
+	}
+	if expanded.Height > 0 {
+		expanded.Alignment = rowAlignment(len(expanded.Pixels) / expanded.Height)
+	}
+	return &expanded
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"bytes"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestImagePixelsSwizzle(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 2, 1))
+	gray.Pix = []byte{10, 20}
+	alpha := image.NewAlpha(image.Rect(0, 0, 2, 1))
+	alpha.Pix = []byte{30, 40}
+	gray16 := image.NewGray16(image.Rect(0, 0, 1, 1))
+	gray16.SetGray16(0, 0, color.Gray16{0x1234})
+	tests := []struct {
+		name           string
+		img            image.Image
+		swizzle        [4]gl.Enum
+		format         gl.Enum
+		internalFormat gl.Enum
+		expanded       []byte
+	}{
+		{"gray", gray, graySwizzle, gl.RGB, gl.RGB8, []byte{10, 10, 10, 20, 20, 20}},
+		{"alpha", alpha, alphaSwizzle, gl.RGBA, gl.RGBA8, []byte{255, 255, 255, 30, 255, 255, 255, 40}},
+		{"gray16", gray16, graySwizzle, gl.RGB, gl.RGB16, bytes.Repeat(swap16([]byte{0x12, 0x34}), 3)},
+	}
+	for _, test := range tests {
+		p := ImagePixels(test.img)
+		if p.Swizzle != test.swizzle || p.Format != gl.RED {
+			t.Errorf("%s: format %v swizzle %v, want RED %v", test.name, p.Format, p.Swizzle, test.swizzle)
+		}
+		e := p.Expand()
+		if e.Format != test.format || e.InternalFormat != test.internalFormat || e.Swizzle != ([4]gl.Enum{}) {
+			t.Errorf("%s: expanded to %+v", test.name, e)
+		}
+		if !bytes.Equal(e.Pixels, test.expanded) {
+			t.Errorf("%s: expanded % x, want % x", test.name, e.Pixels, test.expanded)
+		}
+		if e.Alignment != rowAlignment(len(test.expanded)/e.Height) {
+			t.Errorf("%s: alignment %d", test.name, e.Alignment)
+		}
+	}
+}
+
+func TestSwap16NativeOrder(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 1, 1))
+	img.SetGray16(0, 0, color.Gray16{0x1234})
+	p := ImagePixels(img)
+	if got := *(*uint16)(p.Pointer()); got != 0x1234 {
+		t.Errorf("gl reads %#x, want 0x1234", got)
+	}
+}
+
+func TestUploadPixelsSwizzle(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 1, 1))
+	swizzle := []Call{
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(TEXTURE_SWIZZLE_R), gl.Int(gl.RED)),
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(TEXTURE_SWIZZLE_G), gl.Int(gl.RED)),
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(TEXTURE_SWIZZLE_B), gl.Int(gl.RED)),
+		call("TexParameteri", gl.Enum(gl.TEXTURE_2D), gl.Enum(TEXTURE_SWIZZLE_A), gl.Int(gl.ONE)),
+	}
+	tests := []struct {
+		name         string
+		major, minor int
+		extensions   []string
+		swizzled     bool
+	}{
+		{"3.2", 3, 2, nil, false},
+		{"3.2 with extension", 3, 2, []string{"GL_ARB_texture_swizzle"}, true},
+		{"3.3", 3, 3, nil, true},
+		{"4.1", 4, 1, nil, true},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		r.MajorVersion, r.MinorVersion, r.Extensions = test.major, test.minor, test.extensions
+		previous := SetBackend(r)
+		UploadTexture(1, gray, DefaultTextureOptions)
+		SetBackend(previous)
+		var params []Call
+		var format gl.Enum
+		for _, c := range r.Calls {
+			switch {
+			case c.Name == "TexParameteri" && c.Args[1].(gl.Enum) >= TEXTURE_SWIZZLE_R && c.Args[1].(gl.Enum) <= TEXTURE_SWIZZLE_A:
+				params = append(params, c)
+			case c.Name == "TexImage2D":
+				format = c.Args[6].(gl.Enum)
+			}
+		}
+		if test.swizzled {
+			checkCalls(t, test.name, params, swizzle)
+			if format != gl.RED {
+				t.Errorf("%s: uploaded format %v, want RED", test.name, format)
+			}
+		} else if params != nil || format != gl.RGB {
+			t.Errorf("%s: swizzle %v format %v, want expanded RGB", test.name, params, format)
+		}
+	}
+}
+
+func TestContextInfoCached(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.Extensions = []string{"GL_EXT_texture_filter_anisotropic"}
+	if !HasExtension("GL_EXT_texture_filter_anisotropic") || HasExtension("GL_ARB_texture_swizzle") {
+		t.Error("extension list misread")
+	}
+	queries := len(r.Calls)
+	VersionAtLeast(3, 3)
+	HasExtension("GL_ARB_texture_swizzle")
+	if len(r.Calls) != queries {
+		t.Errorf("context queried again: %v", r.Calls[queries:])
+	}
+}
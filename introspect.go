@@ -0,0 +1,181 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type ProgramVariable struct {
+	Name     string
+	Type     gl.Enum
+	Size     int
+	Location gl.Int
+}
+
+type UniformBlock struct {
+	Name     string
+	Index    gl.Uint
+	DataSize int
+	Binding  gl.Uint
+}
+
+type ProgramInfo struct {
+	Program       gl.Program
+	Uniforms      map[string]ProgramVariable
+	Attributes    map[string]ProgramVariable
+	UniformBlocks map[string]UniformBlock
+}
+
+func InspectProgram(program gl.Program) *ProgramInfo {
+	info := &ProgramInfo{
+		program,
+		make(map[string]ProgramVariable),
+		make(map[string]ProgramVariable),
+		make(map[string]UniformBlock),
+	}
+	var count gl.Int
+	Backend.GetProgramiv(program, gl.ACTIVE_UNIFORMS, &count)
+	for i := gl.Uint(0); i < gl.Uint(count); i++ {
+		name, size, xtype := Backend.GetActiveUniform(program, i)
+		name = variableName(name)
+		location := gl.Int(Backend.GetUniformLocation(program, name))
+		info.Uniforms[name] = ProgramVariable{name, xtype, int(size), location}
+	}
+	Backend.GetProgramiv(program, gl.ACTIVE_ATTRIBUTES, &count)
+	for i := gl.Uint(0); i < gl.Uint(count); i++ {
+		name, size, xtype := Backend.GetActiveAttrib(program, i)
+		name = variableName(name)
+		location := gl.Int(Backend.GetAttribLocation(program, name))
+		info.Attributes[name] = ProgramVariable{name, xtype, int(size), location}
+	}
+	Backend.GetProgramiv(program, gl.ACTIVE_UNIFORM_BLOCKS, &count)
+	for i := gl.Uint(0); i < gl.Uint(count); i++ {
+		var dataSize, binding gl.Int
+		Backend.GetActiveUniformBlockiv(program, i, gl.UNIFORM_BLOCK_DATA_SIZE, &dataSize)
+		Backend.GetActiveUniformBlockiv(program, i, gl.UNIFORM_BLOCK_BINDING, &binding)
+		name := Backend.GetActiveUniformBlockName(program, i)
+		info.UniformBlocks[name] = UniformBlock{name, i, int(dataSize), gl.Uint(binding)}
+	}
+	PanicOnError()
+	return info
+}
+
+// Arrays are reported as "name[0]" but are looked up by their base name.
+func variableName(name string) string {
+	return strings.TrimSuffix(name, "[0]")
+}
+
+func (info *ProgramInfo) UniformLocation(name string) (gl.UniformLocation, bool) {
+	uniform, ok := info.Uniforms[name]
+	if !ok || uniform.Location < 0 {
+		return -1, false
+	}
+	return gl.UniformLocation(uniform.Location), true
+}
+
+func (info *ProgramInfo) AttributeLocation(name string) (gl.AttributeLocation, bool) {
+	attribute, ok := info.Attributes[name]
+	if !ok || attribute.Location < 0 {
+		return 0, false
+	}
+	return gl.AttributeLocation(attribute.Location), true
+}
+
+func (info *ProgramInfo) UniformBlock(name string) (UniformBlock, bool) {
+	block, ok := info.UniformBlocks[name]
+	return block, ok
+}
+
+type BindingReport struct {
+	Program gl.Program
+	Missing []string
+	Unused  []string
+}
+
+func (r *BindingReport) Error() string {
+	lines := []string{fmt.Sprintf("program %d: bindings do not match", r.Program)}
+	for _, name := range r.Missing {
+		lines = append(lines, "missing: "+name)
+	}
+	for _, name := range r.Unused {
+		lines = append(lines, "unused: "+name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckBindings compares the gl tagged location fields of bindings with the
+// program's active variables. A uniform inside a uniform block has no
+// location, so binding one is reported missing.
+func (info *ProgramInfo) CheckBindings(bindings interface{}) *BindingReport {
+	report := &BindingReport{Program: info.Program}
+	bound := map[string]bool{}
+	value := reflect.ValueOf(bindings).Elem()
+	n := value.NumField()
+	for i := 0; i < n; i++ {
+		field := value.Field(i)
+		name := value.Type().Field(i).Tag.Get("gl")
+		if name == "" {
+			continue
+		}
+		var ok bool
+		missing := name
+		switch field.Type() {
+		case uniformLocationType:
+			var uniform ProgramVariable
+			uniform, ok = info.Uniforms[name]
+			if ok && uniform.Location < 0 {
+				ok, missing = false, name+" (in a uniform block)"
+			}
+		case attributeLocationType:
+			_, ok = info.Attributes[name]
+		default:
+			continue
+		}
+		bound[name] = true
+		if !ok {
+			report.Missing = append(report.Missing, missing)
+		}
+	}
+	for name, uniform := range info.Uniforms {
+		if !bound[name] && uniform.Location >= 0 && !strings.HasPrefix(name, "gl_") {
+			report.Unused = append(report.Unused, "uniform "+name)
+		}
+	}
+	for name := range info.Attributes {
+		if !bound[name] && !strings.HasPrefix(name, "gl_") {
+			report.Unused = append(report.Unused, "attribute "+name)
+		}
+	}
+	sort.Strings(report.Unused)
+	return report
+}
+
+// ValidateProgramLocations returns the binding report when a location field
+// names no usable variable. Variables the bindings leave unused only keep
+// their defaults, so they appear in the report but are not an error alone.
+func ValidateProgramLocations(program gl.Program, bindings interface{}) error {
+	report := InspectProgram(program).CheckBindings(bindings)
+	if len(report.Missing) > 0 {
+		return report
+	}
+	return nil
+}
+
+func (lib *ShaderLibrary) InspectProgram(tag string) (*ProgramInfo, bool) {
+	program, ok := lib.GetProgram(tag)
+	if !ok {
+		return nil, false
+	}
+	return InspectProgram(program), true
+}
+
+func (lib *ShaderLibrary) ValidateProgramLocations(tag string, obj interface{}) error {
+	program, ok := lib.GetProgram(tag)
+	if !ok {
+		return fmt.Errorf("program: '%s' not defined", tag)
+	}
+	return ValidateProgramLocations(program, obj)
+}
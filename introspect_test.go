@@ -0,0 +1,49 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"reflect"
+	"testing"
+)
+
+type testProgramBindings struct {
+	ModelView gl.UniformLocation   `gl:"modelview"`
+	Position  gl.AttributeLocation `gl:"position"`
+	Untagged  gl.UniformLocation
+}
+
+func TestValidateProgramLocations(t *testing.T) {
+	modelview := ProgramVariable{"modelview", gl.FLOAT_MAT4, 1, 0}
+	position := ProgramVariable{"position", gl.FLOAT_VEC3, 1, 0}
+	tests := []struct {
+		name       string
+		uniforms   []ProgramVariable
+		attributes []ProgramVariable
+		missing    []string
+		unused     []string
+	}{
+		{"matching", []ProgramVariable{modelview}, []ProgramVariable{position}, nil, nil},
+		{"array", []ProgramVariable{{"modelview[0]", gl.FLOAT_MAT4, 2, 0}}, []ProgramVariable{position}, nil, nil},
+		{"unused",
+			[]ProgramVariable{modelview, {"tint", gl.FLOAT_VEC3, 1, 1}, {"gl_DepthRange.near", gl.FLOAT, 1, 2}, {"Light.color", gl.FLOAT_VEC3, 1, -1}},
+			[]ProgramVariable{position, {"normal", gl.FLOAT_VEC3, 1, 1}, {"gl_VertexID", gl.INT, 1, -1}},
+			nil, []string{"attribute normal", "uniform tint"}},
+		{"missing", []ProgramVariable{modelview}, nil, []string{"position"}, nil},
+		{"block member", []ProgramVariable{{"modelview", gl.FLOAT_MAT4, 1, -1}}, []ProgramVariable{position}, []string{"modelview (in a uniform block)"}, nil},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		previous := SetBackend(r)
+		r.ActiveUniforms, r.ActiveAttributes = test.uniforms, test.attributes
+		report := InspectProgram(1).CheckBindings(&testProgramBindings{})
+		err := ValidateProgramLocations(1, &testProgramBindings{})
+		SetBackend(previous)
+		if !reflect.DeepEqual(report.Missing, test.missing) || !reflect.DeepEqual(report.Unused, test.unused) {
+			t.Errorf("%s: missing %q unused %q, want %q %q", test.name, report.Missing, report.Unused, test.missing, test.unused)
+		}
+		// Unused variables alone do not fail validation.
+		if (err != nil) != (test.missing != nil) {
+			t.Errorf("%s: error %v", test.name, err)
+		}
+	}
+}
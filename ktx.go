@@ -0,0 +1,203 @@
+package render
+
+import (
+	"encoding/binary"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+)
+
+var (
+	ktx1Identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'}
+	ktx2Identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+)
+
+const ktxEndianness = 0x04030201
+
+// ParseKTX reads a KTX 1.1 container. Level data is laid out with rows
+// padded to four bytes, as gl unpacks them by default.
+func ParseKTX(data []byte) (*TextureFile, error) {
+	if len(data) < 64 {
+		return nil, fmt.Errorf("ktx: header truncated")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(data[12:]) != ktxEndianness {
+		order = binary.BigEndian
+		if order.Uint32(data[12:]) != ktxEndianness {
+			return nil, fmt.Errorf("ktx: bad endianness marker")
+		}
+	}
+	var header [13]int
+	for i := range header {
+		header[i] = int(order.Uint32(data[12+4*i:]))
+	}
+	typeSize := header[2]
+	file := &TextureFile{
+		Width:          header[6],
+		Height:         header[7],
+		Depth:          header[8],
+		ArrayLayers:    header[9],
+		Faces:          header[10],
+		InternalFormat: gl.Enum(header[4]),
+		Format:         gl.Enum(header[3]),
+		Type:           gl.Enum(header[1]),
+		Alignment:      4,
+	}
+	if file.Format == 0 {
+		file.Type = 0
+		file.Alignment = 1
+	}
+	if file.Faces != 1 && file.Faces != 6 {
+		return nil, fmt.Errorf("ktx: unsupported face count %d", file.Faces)
+	}
+	levels := header[11]
+	if levels == 0 {
+		levels = 1
+	}
+	if err := file.checkHeader(levels, len(data)); err != nil {
+		return nil, err
+	}
+	offset := 64 + header[12]
+	cube := file.Faces == 6 && file.ArrayLayers == 0
+	for i := 0; i < levels; i++ {
+		sizeBytes, err := byteSlice(data, offset, 4)
+		if err != nil {
+			return nil, err
+		}
+		imageSize := int(order.Uint32(sizeBytes))
+		offset += 4
+		level := file.level(i)
+		if !cube {
+			imageSize /= file.images()
+		}
+		if imageSize == 0 {
+			return nil, fmt.Errorf("ktx: invalid image size for level %d", i)
+		}
+		if imageSize > (len(data)-offset)/file.images() {
+			return nil, fmt.Errorf("ktx: level %d truncated", i)
+		}
+		for j := 0; j < file.images(); j++ {
+			image, err := byteSlice(data, offset, imageSize)
+			if err != nil {
+				return nil, err
+			}
+			if order == binary.BigEndian && typeSize > 1 {
+				image = swapBytes(image, typeSize)
+			}
+			level.Images = append(level.Images, image)
+			offset += imageSize
+			if cube {
+				offset = roundUp(offset, 4)
+			}
+		}
+		offset = roundUp(offset, 4)
+		file.Levels = append(file.Levels, level)
+	}
+	return file, nil
+}
+
+func swapBytes(data []byte, size int) []byte {
+	swapped := make([]byte, len(data))
+	for i := 0; i+size <= len(data); i += size {
+		for j := 0; j < size; j++ {
+			swapped[i+j] = data[i+size-1-j]
+		}
+	}
+	return swapped
+}
+
+// Vulkan formats used by KTX2 which have a gl equivalent.
+var vkFormats = map[uint32]textureFormat{
+	9:   {gl.R8, gl.RED, gl.UNSIGNED_BYTE, 0, 1},
+	16:  {gl.RG8, gl.RG, gl.UNSIGNED_BYTE, 0, 2},
+	23:  {gl.RGB8, gl.RGB, gl.UNSIGNED_BYTE, 0, 3},
+	29:  {gl.SRGB8, gl.RGB, gl.UNSIGNED_BYTE, 0, 3},
+	37:  {gl.RGBA8, gl.RGBA, gl.UNSIGNED_BYTE, 0, 4},
+	43:  {gl.SRGB8_ALPHA8, gl.RGBA, gl.UNSIGNED_BYTE, 0, 4},
+	44:  {gl.RGBA8, gl.BGRA, gl.UNSIGNED_BYTE, 0, 4},
+	50:  {gl.SRGB8_ALPHA8, gl.BGRA, gl.UNSIGNED_BYTE, 0, 4},
+	97:  {gl.RGBA16F, gl.RGBA, gl.HALF_FLOAT, 0, 8},
+	109: {gl.RGBA32F, gl.RGBA, gl.FLOAT, 0, 16},
+	131: compressedFormat(COMPRESSED_RGB_S3TC_DXT1_EXT, 8),
+	132: compressedFormat(COMPRESSED_SRGB_S3TC_DXT1_EXT, 8),
+	133: compressedFormat(COMPRESSED_RGBA_S3TC_DXT1_EXT, 8),
+	134: compressedFormat(COMPRESSED_SRGB_ALPHA_S3TC_DXT1_EXT, 8),
+	135: compressedFormat(COMPRESSED_RGBA_S3TC_DXT3_EXT, 16),
+	136: compressedFormat(COMPRESSED_SRGB_ALPHA_S3TC_DXT3_EXT, 16),
+	137: compressedFormat(COMPRESSED_RGBA_S3TC_DXT5_EXT, 16),
+	138: compressedFormat(COMPRESSED_SRGB_ALPHA_S3TC_DXT5_EXT, 16),
+	139: compressedFormat(COMPRESSED_RED_RGTC1, 8),
+	140: compressedFormat(COMPRESSED_SIGNED_RED_RGTC1, 8),
+	141: compressedFormat(COMPRESSED_RG_RGTC2, 16),
+	142: compressedFormat(COMPRESSED_SIGNED_RG_RGTC2, 16),
+	143: compressedFormat(COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT, 16),
+	144: compressedFormat(COMPRESSED_RGB_BPTC_SIGNED_FLOAT, 16),
+	145: compressedFormat(COMPRESSED_RGBA_BPTC_UNORM, 16),
+	146: compressedFormat(COMPRESSED_SRGB_ALPHA_BPTC_UNORM, 16),
+	147: compressedFormat(COMPRESSED_RGB8_ETC2, 8),
+	148: compressedFormat(COMPRESSED_SRGB8_ETC2, 8),
+	151: compressedFormat(COMPRESSED_RGBA8_ETC2_EAC, 16),
+	152: compressedFormat(COMPRESSED_SRGB8_ALPHA8_ETC2_EAC, 16),
+}
+
+// ParseKTX2 reads a KTX 2.0 container. Supercompressed files and formats
+// without a gl equivalent are rejected.
+func ParseKTX2(data []byte) (*TextureFile, error) {
+	if len(data) < 80 {
+		return nil, fmt.Errorf("ktx2: header truncated")
+	}
+	order := binary.LittleEndian
+	var header [9]int
+	for i := range header {
+		header[i] = int(order.Uint32(data[12+4*i:]))
+	}
+	format, ok := vkFormats[uint32(header[0])]
+	if !ok {
+		return nil, fmt.Errorf("ktx2: unsupported vkFormat %d", header[0])
+	}
+	if header[8] != 0 {
+		return nil, fmt.Errorf("ktx2: unsupported supercompression scheme %d", header[8])
+	}
+	file := &TextureFile{
+		Width:          header[2],
+		Height:         header[3],
+		Depth:          header[4],
+		ArrayLayers:    header[5],
+		Faces:          header[6],
+		InternalFormat: format.InternalFormat,
+		Format:         format.Format,
+		Type:           format.Type,
+		Alignment:      1,
+	}
+	if file.Faces != 1 && file.Faces != 6 {
+		return nil, fmt.Errorf("ktx2: unsupported face count %d", file.Faces)
+	}
+	levels := header[7]
+	if levels == 0 {
+		levels = 1
+	}
+	if err := file.checkHeader(levels, len(data)); err != nil {
+		return nil, err
+	}
+	for i := 0; i < levels; i++ {
+		index, err := byteSlice(data, 80+24*i, 24)
+		if err != nil {
+			return nil, err
+		}
+		offset := int(order.Uint64(index))
+		length := int(order.Uint64(index[8:]))
+		level := file.level(i)
+		imageSize := length / file.images()
+		if imageSize == 0 {
+			return nil, fmt.Errorf("ktx2: invalid image size for level %d", i)
+		}
+		for j := 0; j < file.images(); j++ {
+			image, err := byteSlice(data, offset+j*imageSize, imageSize)
+			if err != nil {
+				return nil, err
+			}
+			level.Images = append(level.Images, image)
+		}
+		file.Levels = append(file.Levels, level)
+	}
+	return file, nil
+}
@@ -0,0 +1,173 @@
+package render
+
+import (
+	"fmt"
+	glm "github.com/Jragonmiris/mathgl"
+	"math"
+	"reflect"
+)
+
+type BlockLayout int
+
+const (
+	Std140 BlockLayout = iota
+	Std430
+)
+
+func (layout BlockLayout) String() string {
+	switch layout {
+	case Std140:
+		return "std140"
+	case Std430:
+		return "std430"
+	}
+	return fmt.Sprintf("BlockLayout(%d)", int(layout))
+}
+
+var (
+	vec2fType = reflect.TypeOf(glm.Vec2f{})
+	vec3fType = reflect.TypeOf(glm.Vec3f{})
+	vec4fType = reflect.TypeOf(glm.Vec4f{})
+	mat3fType = reflect.TypeOf(glm.Mat3f{})
+	mat4fType = reflect.TypeOf(glm.Mat4f{})
+)
+
+func vectorComponents(t reflect.Type) int {
+	switch t {
+	case vec2Type, vec2fType:
+		return 2
+	case vec3Type, vec3fType:
+		return 3
+	case vec4Type, vec4fType, colorType:
+		return 4
+	}
+	return 0
+}
+
+func matrixColumns(t reflect.Type) int {
+	switch t {
+	case mat3Type, mat3fType:
+		return 3
+	case mat4Type, mat4fType:
+		return 4
+	}
+	return 0
+}
+
+func roundUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
+
+// LayoutOf returns the size and base alignment of t in a uniform or storage
+// block. Matrices are column major.
+func LayoutOf(layout BlockLayout, t reflect.Type) (int, int) {
+	if n := vectorComponents(t); n > 0 {
+		align := 16
+		if n == 2 {
+			align = 8
+		}
+		return 4 * n, align
+	}
+	if n := matrixColumns(t); n > 0 {
+		return n * 16, 16
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int32, reflect.Uint32, reflect.Bool:
+		return 4, 4
+	case reflect.Array:
+		stride, align := arrayStride(layout, t)
+		return stride * t.Len(), align
+	case reflect.Struct:
+		size, align := 0, 4
+		for i := 0; i < t.NumField(); i++ {
+			fieldSize, fieldAlign := LayoutOf(layout, t.Field(i).Type)
+			size = roundUp(size, fieldAlign) + fieldSize
+			if fieldAlign > align {
+				align = fieldAlign
+			}
+		}
+		if layout == Std140 {
+			align = roundUp(align, 16)
+		}
+		return roundUp(size, align), align
+	}
+	panic(fmt.Sprintln("type can not be used in a", layout, "block:", t))
+}
+
+func arrayStride(layout BlockLayout, t reflect.Type) (int, int) {
+	size, align := LayoutOf(layout, t.Elem())
+	if layout == Std140 {
+		align = roundUp(align, 16)
+	}
+	return roundUp(size, align), align
+}
+
+func Pack(layout BlockLayout, value interface{}) []byte {
+	v := reflect.Indirect(reflect.ValueOf(value))
+	size, _ := LayoutOf(layout, v.Type())
+	data := make([]byte, size)
+	packValue(layout, data, 0, v)
+	return data
+}
+
+func PackStd140(value interface{}) []byte {
+	return Pack(Std140, value)
+}
+
+func PackStd430(value interface{}) []byte {
+	return Pack(Std430, value)
+}
+
+func packValue(layout BlockLayout, data []byte, offset int, v reflect.Value) {
+	t := v.Type()
+	if n := vectorComponents(t); n > 0 {
+		for i := 0; i < n; i++ {
+			packScalar(data, offset+4*i, v.Index(i))
+		}
+		return
+	}
+	if n := matrixColumns(t); n > 0 {
+		for c := 0; c < n; c++ {
+			for r := 0; r < n; r++ {
+				packScalar(data, offset+c*16+r*4, v.Index(c*n+r))
+			}
+		}
+		return
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		stride, _ := arrayStride(layout, t)
+		for i := 0; i < v.Len(); i++ {
+			packValue(layout, data, offset+i*stride, v.Index(i))
+		}
+	case reflect.Struct:
+		fieldOffset := 0
+		for i := 0; i < t.NumField(); i++ {
+			size, align := LayoutOf(layout, t.Field(i).Type)
+			fieldOffset = roundUp(fieldOffset, align)
+			packValue(layout, data, offset+fieldOffset, v.Field(i))
+			fieldOffset += size
+		}
+	default:
+		packScalar(data, offset, v)
+	}
+}
+
+func packScalar(data []byte, offset int, v reflect.Value) {
+	var bits uint32
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		bits = math.Float32bits(float32(v.Float()))
+	case reflect.Int, reflect.Int32:
+		bits = uint32(int32(v.Int()))
+	case reflect.Uint32:
+		bits = uint32(v.Uint())
+	case reflect.Bool:
+		if v.Bool() {
+			bits = 1
+		}
+	default:
+		panic(fmt.Sprintln("unsupported block scalar:", v.Type()))
+	}
+	nativeOrder.PutUint32(data[offset:], bits)
+}
@@ -0,0 +1,105 @@
+package render
+
+import (
+	glm "github.com/Jragonmiris/mathgl"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type paddedVec3 struct {
+	Position glm.Vec3f
+	Scale    float32
+}
+
+type scalarStruct struct {
+	Value float32
+}
+
+type mixedStruct struct {
+	Weight float32
+	Offset glm.Vec2f
+}
+
+type arrayStruct struct {
+	A float32
+	B [2]float32
+	C float32
+}
+
+func TestLayoutOf(t *testing.T) {
+	tests := []struct {
+		name                    string
+		value                   interface{}
+		std140Size, std140Align int
+		std430Size, std430Align int
+	}{
+		{"float", float32(0), 4, 4, 4, 4},
+		{"int", int32(0), 4, 4, 4, 4},
+		{"bool", false, 4, 4, 4, 4},
+		{"vec2", glm.Vec2f{}, 8, 8, 8, 8},
+		{"vec3", glm.Vec3f{}, 12, 16, 12, 16},
+		{"dvec3 as vec3", glm.Vec3d{}, 12, 16, 12, 16},
+		{"vec4", glm.Vec4f{}, 16, 16, 16, 16},
+		{"mat3", glm.Mat3f{}, 48, 16, 48, 16},
+		{"mat4", glm.Mat4d{}, 64, 16, 64, 16},
+		{"float[3]", [3]float32{}, 48, 16, 12, 4},
+		{"vec2[2]", [2]glm.Vec2f{}, 32, 16, 16, 8},
+		{"vec3[2]", [2]glm.Vec3f{}, 32, 16, 32, 16},
+		{"mat3[2]", [2]glm.Mat3f{}, 96, 16, 96, 16},
+		{"vec3 then float", paddedVec3{}, 16, 16, 16, 16},
+		{"struct of float", scalarStruct{}, 16, 16, 4, 4},
+		{"struct of float[2]", [2]scalarStruct{}, 32, 16, 8, 4},
+		{"struct with vec2[2]", [2]mixedStruct{}, 32, 16, 32, 8},
+		{"struct with float[2]", arrayStruct{}, 64, 16, 16, 4},
+	}
+	for _, test := range tests {
+		typ := reflect.TypeOf(test.value)
+		size, align := LayoutOf(Std140, typ)
+		if size != test.std140Size || align != test.std140Align {
+			t.Errorf("%s: std140 size %d align %d, want %d %d", test.name, size, align, test.std140Size, test.std140Align)
+		}
+		size, align = LayoutOf(Std430, typ)
+		if size != test.std430Size || align != test.std430Align {
+			t.Errorf("%s: std430 size %d align %d, want %d %d", test.name, size, align, test.std430Size, test.std430Align)
+		}
+	}
+}
+
+type packedBlock struct {
+	Position glm.Vec3f
+	Scale    float32
+	Basis    glm.Mat3f
+	Weights  [2]float32
+	Lights   [2]mixedStruct
+}
+
+func TestPack(t *testing.T) {
+	block := packedBlock{
+		glm.Vec3f{1, 2, 3}, 4,
+		glm.Mat3f{5, 6, 7, 8, 9, 10, 11, 12, 13},
+		[2]float32{14, 15},
+		[2]mixedStruct{{16, glm.Vec2f{17, 18}}, {19, glm.Vec2f{20, 21}}},
+	}
+	tests := []struct {
+		layout  BlockLayout
+		size    int
+		offsets []int
+	}{
+		{Std140, 128, []int{0, 4, 8, 12, 16, 20, 24, 32, 36, 40, 48, 52, 56, 64, 80, 96, 104, 108, 112, 120, 124}},
+		{Std430, 112, []int{0, 4, 8, 12, 16, 20, 24, 32, 36, 40, 48, 52, 56, 64, 68, 72, 80, 84, 88, 96, 100}},
+	}
+	for _, test := range tests {
+		data := Pack(test.layout, &block)
+		if len(data) != test.size {
+			t.Errorf("%s: packed %d bytes, want %d", test.layout, len(data), test.size)
+			continue
+		}
+		for i, offset := range test.offsets {
+			value := math.Float32frombits(nativeOrder.Uint32(data[offset:]))
+			if value != float32(i+1) {
+				t.Errorf("%s: offset %d holds %v, want %d", test.layout, offset, value, i+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type ShaderManifest struct {
+	Defines  map[string]string          `json:"defines,omitempty"`
+	Programs map[string]ProgramManifest `json:"programs"`
+}
+
+type ProgramManifest struct {
+	ProgramStages
+	Defines  map[string]string            `json:"defines,omitempty"`
+	Variants map[string]map[string]string `json:"variants,omitempty"`
+}
+
+type ManifestError struct {
+	Filename string
+	Errors   []error
+}
+
+func (e *ManifestError) Error() string {
+	lines := []string{e.Filename + ": failed to load programs"}
+	for _, err := range e.Errors {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func VariantTag(tag, variant string) string {
+	return tag + ":" + variant
+}
+
+func ReadShaderManifest(filename string) (*ShaderManifest, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	manifest := &ShaderManifest{}
+	err = json.NewDecoder(file).Decode(manifest)
+	if err != nil {
+		return nil, err
+	}
+	manifest.resolvePaths(filepath.Dir(filename))
+	return manifest, nil
+}
+
+func (manifest *ShaderManifest) resolvePaths(dir string) {
+	resolve := func(path *string) {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(dir, *path)
+		}
+	}
+	for tag, program := range manifest.Programs {
+		stages := &program.ProgramStages
+		resolve(&stages.Vertex)
+		resolve(&stages.TessControl)
+		resolve(&stages.TessEvaluation)
+		resolve(&stages.Geometry)
+		resolve(&stages.Fragment)
+		resolve(&stages.Compute)
+		manifest.Programs[tag] = program
+	}
+}
+
+func (lib *ShaderLibrary) LoadManifest(filename string) error {
+	manifest, err := ReadShaderManifest(filename)
+	if err != nil {
+		return err
+	}
+	errs := lib.LoadShaderManifest(manifest)
+	if len(errs) > 0 {
+		return &ManifestError{filename, errs}
+	}
+	return nil
+}
+
+func (lib *ShaderLibrary) LoadShaderManifest(manifest *ShaderManifest) []error {
+	errs := make([]error, 0)
+	tags := make([]string, 0, len(manifest.Programs))
+	for tag := range manifest.Programs {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		program := manifest.Programs[tag]
+		options := lib.Options.WithDefines(manifest.Defines, program.Defines)
+		err := lib.BuildProgramWithOptions(tag, program.ProgramStages, options)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		variants := make([]string, 0, len(program.Variants))
+		for variant := range program.Variants {
+			variants = append(variants, variant)
+		}
+		sort.Strings(variants)
+		for _, variant := range variants {
+			variantOptions := options.WithDefines(program.Variants[variant])
+			err := lib.BuildProgramWithOptions(VariantTag(tag, variant), program.ProgramStages, variantOptions)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
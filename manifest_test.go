@@ -0,0 +1,99 @@
+package render
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes each file under dir, creating directories as needed.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadShaderManifest(t *testing.T) {
+	dir := t.TempDir()
+	absolute := filepath.Join(dir, "elsewhere", "a.geom")
+	quoted, _ := json.Marshal(absolute)
+	writeFiles(t, dir, map[string]string{
+		"shaders/manifest.json": `{"programs": {"a": {"vertex": "a.vert", "fragment": "lib/a.frag", "geometry": ` + string(quoted) + `}}}`,
+		"broken.json":           `{"programs": [`,
+	})
+	manifest, err := ReadShaderManifest(filepath.Join(dir, "shaders", "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ProgramStages{
+		Vertex:   filepath.Join(dir, "shaders", "a.vert"),
+		Fragment: filepath.Join(dir, "shaders", "lib", "a.frag"),
+		Geometry: absolute,
+	}
+	if got := manifest.Programs["a"].ProgramStages; got != want {
+		t.Errorf("stages %+v, want %+v", got, want)
+	}
+	for _, name := range []string{"broken.json", "missing.json"} {
+		if _, err := ReadShaderManifest(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s read without error", name)
+		}
+	}
+}
+
+func TestLoadShaderManifest(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"manifest.json": `{
+			"defines": {"QUALITY": "1"},
+			"programs": {
+				"c": {"fragment": "a.frag"},
+				"a": {"vertex": "a.vert", "fragment": "a.frag", "variants": {"z": {"Z": "1"}, "y": {"Y": "1"}}},
+				"b": {"vertex": "a.vert", "fragment": "missing.frag", "variants": {"y": {}, "x": {}}}
+			}
+		}`,
+		"a.vert": "void main() {}\n",
+		"a.frag": "void main() {}\n",
+	})
+	filename := filepath.Join(dir, "manifest.json")
+	lib := NewShaderLibrary()
+	err := lib.LoadManifest(filename)
+	manifestErr, ok := err.(*ManifestError)
+	if !ok || manifestErr.Filename != filename {
+		t.Fatalf("error %v", err)
+	}
+	// Errors follow the sorted tags and variants, whatever order the map
+	// was decoded in.
+	want := []string{"missing.frag", "missing.frag", "missing.frag", "program 'c': program has no vertex stage"}
+	if len(manifestErr.Errors) != len(want) {
+		t.Fatalf("errors %v", manifestErr.Errors)
+	}
+	for i, err := range manifestErr.Errors {
+		if !strings.Contains(err.Error(), want[i]) {
+			t.Errorf("error %d is %v, want %q", i, err, want[i])
+		}
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 5 || lines[0] != filename+": failed to load programs" {
+		t.Errorf("message %q", err.Error())
+	}
+	for _, tag := range []string{"a", VariantTag("a", "y"), VariantTag("a", "z")} {
+		if _, ok := lib.GetProgram(tag); !ok {
+			t.Errorf("program %s not built", tag)
+		}
+	}
+	for _, tag := range []string{"b", VariantTag("b", "x"), "c"} {
+		if _, ok := lib.GetProgram(tag); ok {
+			t.Errorf("program %s built", tag)
+		}
+	}
+}
@@ -34,7 +34,7 @@ type Mesh struct {
 type Polylist struct {
 	VertexData       []float64
 	NormalData       []float64
-	TriangleElements []int16
+	TriangleElements []uint32
 }
 
 func NewIndex(c *collada.Collada) (*Index, error) {
@@ -182,7 +182,7 @@ func (index *Index) createMeshPolyList(pl *collada.Polylist, verticies map[strin
 	mpl := Polylist{
 		make([]float64, 0),
 		make([]float64, 0),
-		make([]int16, 0),
+		make([]uint32, 0),
 	}
 
 	vo, vs, no, ns, stride := index.ReadOffsets(pl.Input, verticies)
@@ -211,16 +211,16 @@ func (index *Index) createMeshPolyList(pl *collada.Polylist, verticies map[strin
 			}
 			switch v {
 			case 3:
-				mpl.TriangleElements = append(mpl.TriangleElements, int16(index))
+				mpl.TriangleElements = append(mpl.TriangleElements, uint32(index))
 			case 4:
 				if j == 3 {
 					//split quad into triangle
 					n := len(mpl.TriangleElements)
 					p0 := mpl.TriangleElements[n-3]
 					p1 := mpl.TriangleElements[n-1]
-					mpl.TriangleElements = append(mpl.TriangleElements, p0, p1, int16(index))
+					mpl.TriangleElements = append(mpl.TriangleElements, p0, p1, uint32(index))
 				} else {
-					mpl.TriangleElements = append(mpl.TriangleElements, int16(index))
+					mpl.TriangleElements = append(mpl.TriangleElements, uint32(index))
 				}
 			default:
 				fmt.Println("unsupported polygon size:", v)
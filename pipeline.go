@@ -1,6 +1,7 @@
 package render
 
 import (
+	"encoding/xml"
 	"errors"
 	"fmt"
 	collada "github.com/GlenKelley/go-collada"
@@ -9,12 +10,14 @@ import (
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime/debug"
-	"unsafe"
 )
 
 type Color [4]gl.Float
@@ -67,6 +70,10 @@ type ShaderLibrary struct {
 	FragmentShaders map[string]gl.FragmentShader
 	VertexShaders   map[string]gl.VertexShader
 	Programs        map[string]gl.Program
+	Shaders         map[string]gl.Uint
+	Options         ShaderOptions
+	BinaryCache     *ProgramBinaryCache
+	ReadFile        ReadFileFunc
 }
 
 func NewShaderLibrary() ShaderLibrary {
@@ -74,14 +81,25 @@ func NewShaderLibrary() ShaderLibrary {
 		make(map[string]gl.FragmentShader),
 		make(map[string]gl.VertexShader),
 		make(map[string]gl.Program),
+		make(map[string]gl.Uint),
+		DefaultShaderOptions,
+		nil,
+		nil,
 	}
 }
 
+func (lib *ShaderLibrary) readFile() ReadFileFunc {
+	if lib.ReadFile != nil {
+		return lib.ReadFile
+	}
+	return ioutil.ReadFile
+}
+
 func (lib *ShaderLibrary) LoadFragmentShader(tag, filename string) {
 	_, ok := lib.FragmentShaders[tag]
 	if !ok {
-		shader := gl.FragmentShader(gl.CreateShader(gl.FRAGMENT_SHADER))
-		err := LoadFragmentShaderSource(shader, filename)
+		shader := gl.FragmentShader(CreateShader(gl.FRAGMENT_SHADER))
+		err := LoadShaderWith(lib.readFile(), gl.Uint(shader), filename, lib.Options)
 		if err != nil {
 			panic(err)
 		}
@@ -92,8 +110,8 @@ func (lib *ShaderLibrary) LoadFragmentShader(tag, filename string) {
 func (lib *ShaderLibrary) LoadVertexShader(tag, filename string) {
 	_, ok := lib.VertexShaders[tag]
 	if !ok {
-		shader := gl.VertexShader(gl.CreateShader(gl.VERTEX_SHADER))
-		err := LoadVertexShaderSource(shader, filename)
+		shader := gl.VertexShader(CreateShader(gl.VERTEX_SHADER))
+		err := LoadShaderWith(lib.readFile(), gl.Uint(shader), filename, lib.Options)
 		if err != nil {
 			panic(err)
 		}
@@ -106,7 +124,7 @@ func (lib *ShaderLibrary) LoadProgram(tag, vsfilename, fsfilename string) {
 	ftag := tag + "_fs"
 	lib.LoadVertexShader(vtag, vsfilename)
 	lib.LoadFragmentShader(ftag, fsfilename)
-	program := gl.CreateProgram()
+	program := CreateProgram()
 	err := LoadProgram(program, lib.VertexShaders[vtag], lib.FragmentShaders[ftag])
 	if err != nil {
 		panic(err)
@@ -129,7 +147,7 @@ func (lib *ShaderLibrary) BindProgramLocations(tag string, obj interface{}) {
 func (lib *ShaderLibrary) UseProgram(tag string) {
 	program, ok := lib.GetProgram(tag)
 	if ok {
-		gl.UseProgram(program)
+		Backend.UseProgram(program)
 	}
 }
 
@@ -147,115 +165,42 @@ func MatArray(d glm.Mat4d) *gl.Float {
 	return &f[0]
 }
 
+// ArrayPtr converts []float64 to floats and []int to unsigned shorts, as
+// callers pairing it with VertexAttribPointer expect, and returns any other
+// slice as it is. Use ArrayData to upload data exactly as its layout says.
 func ArrayPtr(data interface{}) (gl.Pointer, gl.Sizeiptr) {
-	var size gl.Sizeiptr
-	var ptr gl.Pointer
 	switch data := data.(type) {
 	case []float64:
-		duplicate := make([]gl.Float, len(data))
-		for i, v := range data {
-			duplicate[i] = gl.Float(v)
-		}
-		ptr, size = ArrayPtr(duplicate)
-	case []float32:
-		if len(data) == 0 {
-			size = 0
-			ptr = gl.Pointer(nil)
-		} else {
-			var v float32
-			size = gl.Sizeiptr(len(data) * int(unsafe.Sizeof(v)))
-			ptr = gl.Pointer(&data[0])
-		}
-	case []gl.Float:
-		if len(data) == 0 {
-			size = 0
-			ptr = gl.Pointer(nil)
-		} else {
-			var v gl.Float
-			size = gl.Sizeiptr(len(data) * int(unsafe.Sizeof(v)))
-			ptr = gl.Pointer(&data[0])
-		}
-	case []int16:
-		if len(data) == 0 {
-			size = 0
-			ptr = gl.Pointer(nil)
-		} else {
-			var v int16
-			size = gl.Sizeiptr(len(data) * int(unsafe.Sizeof(v)))
-			ptr = gl.Pointer(&data[0])
-		}
-	case []gl.Ushort:
-		if len(data) == 0 {
-			size = 0
-			ptr = gl.Pointer(nil)
-		} else {
-			var v gl.Ushort
-			size = gl.Sizeiptr(len(data) * int(unsafe.Sizeof(v)))
-			ptr = gl.Pointer(&data[0])
-		}
+		data32 := Float32s(data)
+		ptr, size, _ := ArrayData(data32)
+		return ptr, size
 	case []int:
 		duplicate := make([]gl.Ushort, len(data))
 		for i, v := range data {
 			duplicate[i] = gl.Ushort(v)
 		}
-		ptr, size = ArrayPtr(duplicate)
-	case []gl.Int:
-		if len(data) == 0 {
-			size = 0
-			ptr = gl.Pointer(nil)
-		} else {
-			var v gl.Int
-			size = gl.Sizeiptr(len(data) * int(unsafe.Sizeof(v)))
-			ptr = gl.Pointer(&data[0])
-		}
-	default:
-		panic(fmt.Sprintln("unknown data type:", reflect.TypeOf(data)))
+		ptr, size, _ := ArrayData(duplicate)
+		return ptr, size
 	}
+	ptr, size, _ := ArrayData(data)
 	return ptr, size
 }
 
-func BindArrayData(buffer gl.Buffer, data interface{}) {
-	ptr, size := ArrayPtr(data)
-	gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
-	gl.BufferData(gl.ARRAY_BUFFER, size, ptr, gl.STATIC_DRAW)
+func Float32s(data []float64) []float32 {
+	f := make([]float32, len(data))
+	for i, v := range data {
+		f[i] = float32(v)
+	}
+	return f
 }
 
-func ImageData(img image.Image) (gl.Sizei, gl.Sizei, gl.Enum, gl.Enum, gl.Pointer) {
-	switch img := img.(type) {
-	case *image.NRGBA:
-		return gl.Sizei(img.Rect.Dx()), gl.Sizei(img.Rect.Dy()), gl.RGBA, gl.UNSIGNED_BYTE, gl.Pointer(&img.Pix[0])
-	case *image.RGBA:
-		return gl.Sizei(img.Rect.Dx()), gl.Sizei(img.Rect.Dy()), gl.RGBA, gl.UNSIGNED_BYTE, gl.Pointer(&img.Pix[0])
-	default:
-		panic(reflect.TypeOf(img))
-	}
-	return 0, 0, gl.RGB, gl.UNSIGNED_BYTE, nil
+func BindArrayData(buffer gl.Buffer, data interface{}) {
+	BindArrayDataUsage(buffer, data, gl.STATIC_DRAW)
 }
 
-func LoadTexture(texture gl.Texture, filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return err
-	}
-	width, height, format, channelType, pixels := ImageData(img)
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	gl.TexImage2D(
-		gl.TEXTURE_2D, 0, /* target, level of detail */
-		gl.RGB8,          /* internal format */
-		width, height, 0, /* width, height, border */
-		format, channelType, /* external format, type */
-		pixels, /* pixels */
-	)
-	return nil
+func ImageData(img image.Image) (gl.Sizei, gl.Sizei, gl.Enum, gl.Enum, gl.Pointer) {
+	p := ImagePixels(img).Expand()
+	return gl.Sizei(p.Width), gl.Sizei(p.Height), p.Format, p.Type, p.Pointer()
 }
 
 func LoadVertexShaderSource(shader gl.VertexShader, filename string) error {
@@ -267,32 +212,73 @@ func LoadFragmentShaderSource(shader gl.FragmentShader, filename string) error {
 }
 
 func LoadShader(shader gl.Uint, filename string) error {
-	bytes, err := ioutil.ReadFile(filename)
+	return LoadShaderWithOptions(shader, filename, DefaultShaderOptions)
+}
+
+func LoadShaderWithOptions(shader gl.Uint, filename string, options ShaderOptions) error {
+	return LoadShaderWith(ioutil.ReadFile, shader, filename, options)
+}
+
+// FSReadFile reads shader sources and their includes from fsys.
+func FSReadFile(fsys fs.FS) ReadFileFunc {
+	return func(filename string) ([]byte, error) {
+		return fs.ReadFile(fsys, filepath.ToSlash(filepath.Clean(filename)))
+	}
+}
+
+func LoadShaderFS(shader gl.Uint, fsys fs.FS, name string, options ShaderOptions) error {
+	return LoadShaderWith(FSReadFile(fsys), shader, name, options)
+}
+
+// LoadShaderFrom compiles the source read from r. Since there is nowhere to
+// find them, the source may not #include other files.
+func LoadShaderFrom(shader gl.Uint, r io.Reader, name string, options ShaderOptions) error {
+	source, err := ioutil.ReadAll(r)
+	if err != nil {
+		DeleteShader(shader)
+		return err
+	}
+	readFile := func(filename string) ([]byte, error) {
+		if filename == name {
+			return source, nil
+		}
+		return nil, &os.PathError{Op: "include", Path: filename, Err: os.ErrNotExist}
+	}
+	return LoadShaderWith(readFile, shader, name, options)
+}
+
+func LoadShaderWith(readFile ReadFileFunc, shader gl.Uint, filename string, options ShaderOptions) error {
+	source, err := PreprocessShaderWith(readFile, filename, options)
 	if err != nil {
+		DeleteShader(shader)
 		return err
 	}
-	source := string(bytes)
-	gl.ShaderSource(shader, []string{source})
-	gl.CompileShader(shader)
+	Backend.ShaderSource(shader, []string{source.Source})
+	Backend.CompileShader(shader)
 	var ok gl.Int
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &ok)
+	Backend.GetShaderiv(shader, gl.COMPILE_STATUS, &ok)
 	if ok == 0 {
-		fmt.Fprintln(os.Stderr, gl.GetShaderInfoLog(shader))
-		gl.DeleteShader(shader)
-		return errors.New("Failed to compile " + filename + "\n")
+		log := Backend.GetShaderInfoLog(shader)
+		DeleteShader(shader)
+		return &ShaderCompileError{filename, log, ParseShaderLog(log, source)}
 	}
 	return nil
 }
 
 func LoadProgram(program gl.Program, vertexShader gl.VertexShader, fragmentShader gl.FragmentShader) error {
-	gl.AttachShader(program, gl.Uint(vertexShader))
-	gl.AttachShader(program, gl.Uint(fragmentShader))
-	gl.LinkProgram(program)
+	return LinkShaders(program, gl.Uint(vertexShader), gl.Uint(fragmentShader))
+}
+
+func LinkShaders(program gl.Program, shaders ...gl.Uint) error {
+	for _, shader := range shaders {
+		Backend.AttachShader(program, shader)
+	}
+	Backend.LinkProgram(program)
 	var ok gl.Int
-	gl.GetProgramiv(program, gl.LINK_STATUS, &ok)
+	Backend.GetProgramiv(program, gl.LINK_STATUS, &ok)
 	if ok == 0 {
-		fmt.Fprintln(os.Stderr, gl.GetProgramInfoLog(program))
-		gl.DeleteProgram(program)
+		fmt.Fprintln(os.Stderr, Backend.GetProgramInfoLog(program))
+		DeleteProgram(program)
 		return errors.New("Failed to link shader program")
 	}
 	return nil
@@ -315,22 +301,22 @@ func Bind(bindings interface{}) {
 		field := value.Field(i)
 		switch field.Type() {
 		case bufferType:
-			buffer := gl.GenBuffer()
+			buffer := GenBuffer()
 			field.Set(reflect.ValueOf(buffer))
 		case textureType:
-			texture := gl.GenTexture()
+			texture := GenTexture()
 			field.Set(reflect.ValueOf(texture))
 		case vaoType:
-			vao := gl.GenVertexArray()
+			vao := GenVertexArray()
 			field.Set(reflect.ValueOf(vao))
 		case vertexShaderType:
-			shader := gl.VertexShader(gl.CreateShader(gl.VERTEX_SHADER))
+			shader := gl.VertexShader(CreateShader(gl.VERTEX_SHADER))
 			field.Set(reflect.ValueOf(shader))
 		case fragmentShaderType:
-			shader := gl.FragmentShader(gl.CreateShader(gl.FRAGMENT_SHADER))
+			shader := gl.FragmentShader(CreateShader(gl.FRAGMENT_SHADER))
 			field.Set(reflect.ValueOf(shader))
 		case programType:
-			program := gl.CreateProgram()
+			program := CreateProgram()
 			field.Set(reflect.ValueOf(program))
 		}
 	}
@@ -345,11 +331,11 @@ func BindProgramLocations(program gl.Program, bindings interface{}) {
 		if name != "" {
 			switch field.Type() {
 			case uniformLocationType:
-				location := gl.GetUniformLocation(program, name)
+				location := Backend.GetUniformLocation(program, name)
 				field.Set(reflect.ValueOf(location))
 				PanicOnError()
 			case attributeLocationType:
-				location := gl.GetAttribLocation(program, name)
+				location := Backend.GetAttribLocation(program, name)
 				field.Set(reflect.ValueOf(location))
 				PanicOnError()
 			}
@@ -358,13 +344,13 @@ func BindProgramLocations(program gl.Program, bindings interface{}) {
 }
 
 func AttachTexture(location gl.UniformLocation, textureEnum gl.Enum, target gl.Enum, texture gl.Texture) {
-	gl.ActiveTexture(textureEnum)
-	gl.BindTexture(target, texture)
-	gl.Uniform1i(location, gl.Int(textureEnum-gl.TEXTURE0))
+	Backend.ActiveTexture(textureEnum)
+	Backend.BindTexture(target, texture)
+	Backend.Uniform1i(location, gl.Int(textureEnum-gl.TEXTURE0))
 }
 
 func PanicOnError() {
-	err := gl.GetError()
+	err := Backend.GetError()
 	if err != gl.NO_ERROR {
 		switch err {
 		case gl.INVALID_ENUM:
@@ -391,7 +377,7 @@ type StencilOp struct {
 var Stencil StencilOp
 
 func (s *StencilOp) Enable() *StencilOp {
-	gl.Enable(gl.STENCIL_TEST)
+	Backend.Enable(gl.STENCIL_TEST)
 	s.Draw()
 	s.Mask(0)
 	s.Keep()
@@ -402,7 +388,7 @@ func (s *StencilOp) Enable() *StencilOp {
 }
 
 func (s *StencilOp) Disable() *StencilOp {
-	gl.Disable(gl.STENCIL_TEST)
+	Backend.Disable(gl.STENCIL_TEST)
 	s.Draw()
 	s.Depth()
 	s.DepthMask()
@@ -411,77 +397,77 @@ func (s *StencilOp) Disable() *StencilOp {
 }
 
 func (s *StencilOp) Draw() *StencilOp {
-	gl.ColorMask(gl.TRUE, gl.TRUE, gl.TRUE, gl.TRUE)
+	Backend.ColorMask(gl.TRUE, gl.TRUE, gl.TRUE, gl.TRUE)
 	return s
 }
 
 func (s *StencilOp) DepthLE() *StencilOp {
-	gl.DepthFunc(gl.LEQUAL)
+	Backend.DepthFunc(gl.LEQUAL)
 	return s
 }
 
 func (s *StencilOp) DepthLT() *StencilOp {
-	gl.DepthFunc(gl.LESS)
+	Backend.DepthFunc(gl.LESS)
 	return s
 }
 
 func (s *StencilOp) DepthAlways() *StencilOp {
-	gl.DepthFunc(gl.ALWAYS)
+	Backend.DepthFunc(gl.ALWAYS)
 	return s
 }
 
 func (s *StencilOp) NoDraw() *StencilOp {
-	gl.ColorMask(gl.FALSE, gl.FALSE, gl.FALSE, gl.FALSE)
+	Backend.ColorMask(gl.FALSE, gl.FALSE, gl.FALSE, gl.FALSE)
 	return s
 }
 
 func (s *StencilOp) Mask(level int) *StencilOp {
-	gl.StencilFunc(gl.EQUAL, gl.Int(level), ^gl.Uint(0))
+	Backend.StencilFunc(gl.EQUAL, gl.Int(level), ^gl.Uint(0))
 	return s
 }
 
 func (s *StencilOp) Unmask(level int) *StencilOp {
-	gl.StencilFunc(gl.ALWAYS, gl.Int(level), ^gl.Uint(0))
+	Backend.StencilFunc(gl.ALWAYS, gl.Int(level), ^gl.Uint(0))
 	return s
 }
 
 func (s *StencilOp) Depth() *StencilOp {
-	gl.Enable(gl.DEPTH_TEST)
+	Backend.Enable(gl.DEPTH_TEST)
 	return s
 }
 
 func (s *StencilOp) NoDepth() *StencilOp {
-	gl.Disable(gl.DEPTH_TEST)
+	Backend.Disable(gl.DEPTH_TEST)
 	return s
 }
 
 func (s *StencilOp) DepthMask() *StencilOp {
-	gl.DepthMask(gl.TRUE)
+	Backend.DepthMask(gl.TRUE)
 	return s
 }
 
 func (s *StencilOp) NoDepthMask() *StencilOp {
-	gl.DepthMask(gl.FALSE)
+	Backend.DepthMask(gl.FALSE)
 	return s
 }
 
 func (s *StencilOp) Keep() *StencilOp {
-	gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+	Backend.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
 	return s
 }
 
 func (s *StencilOp) Replace() *StencilOp {
-	gl.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
+	Backend.StencilOp(gl.KEEP, gl.KEEP, gl.REPLACE)
 	return s
 }
 
 func (s *StencilOp) Increment() *StencilOp {
-	gl.StencilOp(gl.KEEP, gl.KEEP, gl.INCR)
+	Backend.StencilOp(gl.KEEP, gl.KEEP, gl.INCR)
 	return s
 }
 
 func (s *StencilOp) Decrement() *StencilOp {
-	gl.StencilOp(gl.KEEP, gl.KEEP, gl.DECR)
+	Backend.StencilOp(gl.KEEP, gl.KEEP, gl.DECR)
 	return s
 }
 
@@ -492,6 +478,7 @@ type Model struct {
 	Geometry  []*Geometry
 	Children  []*Model
    Parent    *Model
+	Shared    []*SharedGeometry
 }
 
 type Geometry struct {
@@ -499,12 +486,14 @@ type Geometry struct {
 	VertexBuffer gl.Buffer
 	NormalBuffer gl.Buffer
 	Elements     []*DrawElements
+	Streams      []*VertexStream
 }
 
 type DrawElements struct {
-	Buffer   gl.Buffer
-	DrawType gl.Enum
-	Count    int
+	Buffer    gl.Buffer
+	DrawType  gl.Enum
+	Count     int
+	IndexType gl.Enum
 }
 
 func (model *Model) WorldTransform() glm.Mat4d {
@@ -530,6 +519,7 @@ func NewModel(name string, children []*Model, geometry []*Geometry, transform gl
 		geometry,
 		children,
       nil,
+		nil,
 	}
    for _, child := range children {
       child.Parent = model
@@ -545,6 +535,7 @@ func EmptyModel(name string) *Model {
 		[]*Geometry{},
 		[]*Model{},
       nil,
+		nil,
 	}
 }
 
@@ -580,18 +571,26 @@ func NewGeometry(name string, verticies, normals []float64, elements []*DrawElem
 	var vertexBuffer gl.Buffer = 0
 	var normalBuffer gl.Buffer = 0
 	if len(verticies) > 0 {
-		vertexBuffer = gl.GenBuffer()
-		BindArrayData(vertexBuffer, verticies)
+		vertexBuffer = GenBuffer()
+		BindArrayData(vertexBuffer, Float32s(verticies))
 	}
 	if len(normals) > 0 {
-		normalBuffer = gl.GenBuffer()
-		BindArrayData(normalBuffer, normals)
+		normalBuffer = GenBuffer()
+		BindArrayData(normalBuffer, Float32s(normals))
+	}
+	streams := make([]*VertexStream, 0, 2)
+	if vertexBuffer != 0 {
+		streams = append(streams, &VertexStream{vertexBuffer, NewVertexFormat(PositionAttribute)})
+	}
+	if normalBuffer != 0 {
+		streams = append(streams, &VertexStream{normalBuffer, NewVertexFormat(NormalAttribute)})
 	}
 	return &Geometry{
 		name,
 		vertexBuffer,
 		normalBuffer,
 		elements,
+		streams,
 	}
 }
 
@@ -602,12 +601,57 @@ func (geometry *Geometry) AddDrawElements(drawElements *DrawElements) {
 func NewDrawElements(elements []int16, drawType gl.Enum) *DrawElements {
 	count := len(elements)
 	if count > 0 {
-		buffer := gl.GenBuffer()
+		buffer := GenBuffer()
 		BindArrayData(buffer, elements)
 		return &DrawElements{
 			buffer,
 			drawType,
 			count,
+			gl.UNSIGNED_SHORT,
+		}
+	}
+	return nil
+}
+
+func IndexType(vertexCount int) gl.Enum {
+	switch {
+	case vertexCount <= math.MaxUint8+1:
+		return gl.UNSIGNED_BYTE
+	case vertexCount <= math.MaxUint16+1:
+		return gl.UNSIGNED_SHORT
+	}
+	return gl.UNSIGNED_INT
+}
+
+func PackIndices(indices []uint32, indexType gl.Enum) interface{} {
+	switch indexType {
+	case gl.UNSIGNED_BYTE:
+		packed := make([]uint8, len(indices))
+		for i, v := range indices {
+			packed[i] = uint8(v)
+		}
+		return packed
+	case gl.UNSIGNED_SHORT:
+		packed := make([]uint16, len(indices))
+		for i, v := range indices {
+			packed[i] = uint16(v)
+		}
+		return packed
+	}
+	return indices
+}
+
+func NewIndexedDrawElements(indices []uint32, vertexCount int, drawType gl.Enum) *DrawElements {
+	count := len(indices)
+	if count > 0 {
+		indexType := IndexType(vertexCount)
+		buffer := GenBuffer()
+		BindArrayData(buffer, PackIndices(indices, indexType))
+		return &DrawElements{
+			buffer,
+			drawType,
+			count,
+			indexType,
 		}
 	}
 	return nil
@@ -651,53 +695,108 @@ func Quaternion(m glm.Mat3d) glm.Quatd {
 }
 
 func LoadSceneAsModel(filename string) (*Model, error) {
+	index, err := ReadScene(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewSceneModel(index), nil
+}
+
+func LoadSceneAsModelFrom(r io.Reader) (*Model, error) {
+	index, err := DecodeScene(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewSceneModel(index), nil
+}
+
+func LoadSceneAsModelFS(fsys fs.FS, name string) (*Model, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadSceneAsModelFrom(file)
+}
+
+func ReadScene(filename string) (*Index, error) {
 	doc, err := collada.LoadDocument(filename)
 	if err != nil {
 		return nil, err
 	}
-	index, err := NewIndex(doc)
+	return NewIndex(doc)
+}
+
+func DecodeScene(r io.Reader) (*Index, error) {
+	doc := &collada.Collada{}
+	err := xml.NewDecoder(r).Decode(doc)
 	if err != nil {
 		return nil, err
 	}
+	return NewIndex(doc)
+}
+
+func NewSceneModel(index *Index) *Model {
+	geometryTemplates := make(map[collada.Id][]*SharedGeometry)
+	for id, mesh := range index.Mesh {
+		addMeshGeometry(id, mesh, geometryTemplates)
+	}
+	return AssembleSceneModel(index, geometryTemplates)
+}
+
+func NewMeshGeometry(id collada.Id, mesh *Mesh) []*SharedGeometry {
+	geometryTemplates := make(map[collada.Id][]*SharedGeometry)
+	addMeshGeometry(id, mesh, geometryTemplates)
+	return geometryTemplates[id]
+}
+
+// addMeshGeometry uploads each polylist of mesh, adding it to
+// geometryTemplates as soon as it exists so that a panic partway through
+// leaves every earlier upload where the caller can delete it.
+func addMeshGeometry(id collada.Id, mesh *Mesh, geometryTemplates map[collada.Id][]*SharedGeometry) {
+	for _, pl := range mesh.Polylist {
+		elements := make([]*DrawElements, 0)
+		drawElements := NewIndexedDrawElements(pl.TriangleElements, len(pl.VertexData)/3, gl.TRIANGLES)
+		if drawElements != nil {
+			elements = append(elements, drawElements)
+		}
+		geometry := NewGeometry(string(id), pl.VertexData, pl.NormalData, elements)
+		geometryTemplates[id] = append(geometryTemplates[id], NewSharedGeometry(geometry))
+	}
+}
+
+// AssembleSceneModel builds the node hierarchy over uploaded geometry,
+// deleting any geometry no node refers to.
+func AssembleSceneModel(index *Index, geometryTemplates map[collada.Id][]*SharedGeometry) *Model {
 	model := EmptyModel("scene")
-	switch doc.Asset.UpAxis {
+	switch index.Collada.Asset.UpAxis {
 	case collada.Xup:
 	case collada.Yup:
 	case collada.Zup:
 		model.Transform = glm.HomogRotate3DXd(-90).Mul4(glm.HomogRotate3DZd(90))
 	}
-
-	geometryTemplates := make(map[collada.Id][]*Geometry)
-	for id, mesh := range index.Mesh {
-		geoms := make([]*Geometry, 0)
-		for _, pl := range mesh.Polylist {
-			elements := make([]*DrawElements, 0)
-			drawElements := NewDrawElements(pl.TriangleElements, gl.TRIANGLES)
-			if drawElements != nil {
-				elements = append(elements, drawElements)
-			}
-			geometry := NewGeometry(string(id), pl.VertexData, pl.NormalData, elements)
-			geoms = append(geoms, geometry)
-		}
-		if len(geoms) > 0 {
-			geometryTemplates[id] = geoms
-		}
-	}
 	for _, node := range index.VisualScene.Node {
 		child, ok := LoadModel(index, node, geometryTemplates)
 		if ok {
 			model.AddChild(child)
 		}
 	}
-	return model, nil
+	for _, geoms := range geometryTemplates {
+		for _, shared := range geoms {
+			if shared.Refs() == 0 {
+				shared.Geometry.Delete()
+			}
+		}
+	}
+	return model
 }
 
-func LoadModel(index *Index, node *collada.Node, geometryTemplates map[collada.Id][]*Geometry) (*Model, bool) {
+func LoadModel(index *Index, node *collada.Node, geometryTemplates map[collada.Id][]*SharedGeometry) (*Model, bool) {
 	transform, ok := index.Transforms[node.Id]
 	if !ok {
 		panic("no transform for id " + node.Id)
 	}
-	geoms := make([]*Geometry, 0)
+	geoms := make([]*SharedGeometry, 0)
 	children := make([]*Model, 0)
 	for _, geoinstance := range node.InstanceGeometry {
 		geoid, _ := geoinstance.Url.Id()
@@ -709,13 +808,14 @@ func LoadModel(index *Index, node *collada.Node, geometryTemplates map[collada.I
 			children = append(children, child)
 		}
 	}
-	model := NewModel(node.Name, children, geoms, transform)
+	model := NewModel(node.Name, children, []*Geometry{}, transform)
+	model.AddSharedGeometry(geoms...)
 	return model, len(geoms) > 0 || len(children) > 0
 }
 
 func DrawModel(mv glm.Mat4d, model *Model, modelview gl.UniformLocation, vertexAttribute gl.AttributeLocation, vao gl.VertexArrayObject) {
 	mv2 := mv.Mul4(model.Transform)
-	gl.UniformMatrix4fv(modelview, 1, gl.FALSE, MatArray(mv2))
+	Backend.UniformMatrix4fv(modelview, 1, gl.FALSE, MatArray(mv2))
 	for _, geo := range model.Geometry {
 		DrawGeometry(geo, vertexAttribute, vao)
 	}
@@ -724,17 +824,11 @@ func DrawModel(mv glm.Mat4d, model *Model, modelview gl.UniformLocation, vertexA
 	}
 }
 
+// DrawGeometry binds the position attribute of the geometry's streams to
+// vertexAttribute. DrawGeometryAttributes binds normals and any other
+// attributes by name.
 func DrawGeometry(geo *Geometry, vertexAttribute gl.AttributeLocation, vao gl.VertexArrayObject) {
-	gl.BindBuffer(gl.ARRAY_BUFFER, geo.VertexBuffer)
-	gl.BindVertexArray(vao)
-	gl.VertexAttribPointer(vertexAttribute, 3, gl.FLOAT, gl.FALSE, 12, nil)
-	gl.EnableVertexAttribArray(vertexAttribute)
-	for _, elem := range geo.Elements {
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, elem.Buffer)
-		gl.DrawElements(elem.DrawType, gl.Sizei(elem.Count), gl.UNSIGNED_SHORT, nil)
-		PanicOnError()
-	}
-	gl.DisableVertexAttribArray(vertexAttribute)
+	DrawGeometryAttributes(geo, map[string]gl.AttributeLocation{PositionAttribute.Name: vertexAttribute}, vao)
 }
 
 func Wheel() *Geometry {
@@ -743,12 +837,12 @@ func Wheel() *Geometry {
 
 	vs := make([]float64, 0, 4*n*3)
 	ns := make([]float64, 0, 4*n*3)
-	ec := int16(0)
+	ec := uint32(0)
    
    de := make([]*DrawElements, 0, 3)
    for w := -1; w <= 1; w += 2 {
       wf := float64(w)
-   	es := make([]int16, 0, 1 + n)
+   	es := make([]uint32, 0, 1 + n)
       
       vs = append(vs, 0,0,float64(w))
       ns = append(ns, 0,0,1)
@@ -763,10 +857,10 @@ func Wheel() *Geometry {
    		es = append(es, ec)
    		ec += 1
    	}
-      de = append(de, NewDrawElements(es, gl.TRIANGLE_FAN))
+      de = append(de, NewIndexedDrawElements(es, len(vs)/3, gl.TRIANGLE_FAN))
    }
 
-	es := make([]int16, 0, 2*n)
+	es := make([]uint32, 0, 2*n)
    w := 1.0
 	for i := 0; i <= n; i++ {
       theta := float64(i) / float64(n) * 2 * math.Pi
@@ -777,7 +871,7 @@ func Wheel() *Geometry {
 		es = append(es, ec, ec+1)
 		ec += 2
    }
-   de = append(de, NewDrawElements(es, gl.TRIANGLE_STRIP))
+   de = append(de, NewIndexedDrawElements(es, len(vs)/3, gl.TRIANGLE_STRIP))
 	return NewGeometry("wheel-mesh", vs, ns, de)
 }
 
@@ -787,8 +881,8 @@ func Sphere() *Geometry {
 
 	vs := make([]float64, 0)
 	ns := make([]float64, 0)
-	es := make([]int16, 0)
-	ec := int16(0)
+	es := make([]uint32, 0)
+	ec := uint32(0)
    
    de := make([]*DrawElements, 0)
    delta := math.Pi * 2 / float64(n)
@@ -812,11 +906,11 @@ func Sphere() *Geometry {
          
          vs = append(vs, x,y,z, xt,yt,z, xtp,ytp,zp, x,y,z, xp,yp,zp, xtp,ytp,zp)
    		ns = append(ns, x,y,z, xt,yt,z, xtp,ytp,zp, x,y,z, xp,yp,zp, xtp,ytp,zp)
-   		es = append(es, ec, ec+1, ec+2, ec+3, ec+4, ec+5, ec+6)
+   		es = append(es, ec, ec+1, ec+2, ec+3, ec+4, ec+5)
          ec += 6
       }
    }
-   de = append(de, NewDrawElements(es, gl.TRIANGLES))
+   de = append(de, NewIndexedDrawElements(es, len(vs)/3, gl.TRIANGLES))
 	return NewGeometry("sphere-mesh", vs, ns, de)
 }
 
@@ -824,8 +918,8 @@ func Sphere() *Geometry {
 func Grid(n int) *Geometry {
 	vs := make([]float64, 0, n*12)
 	ns := make([]float64, 0, n*12)
-	es := make([]int16, 0, n*4)
-	ec := int16(0)
+	es := make([]uint32, 0, n*4)
+	ec := uint32(0)
 	for i := -n; i <= n; i++ {
 		nd := float64(n)
 		id := float64(i)
@@ -834,7 +928,7 @@ func Grid(n int) *Geometry {
 		es = append(es, ec, ec+1, ec+2, ec+3)
 		ec += 4
 	}
-	return NewGeometry("grid-mesh", vs, ns, []*DrawElements{NewDrawElements(es, gl.LINES)})
+	return NewGeometry("grid-mesh", vs, ns, []*DrawElements{NewIndexedDrawElements(es, len(vs)/3, gl.LINES)})
 }
 
 func V3(v glm.Vec4d) glm.Vec3d {
@@ -0,0 +1,290 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+	glm "github.com/Jragonmiris/mathgl"
+	"reflect"
+	"testing"
+)
+
+func call(name string, args ...interface{}) Call {
+	return Call{name, args}
+}
+
+func checkCalls(t *testing.T, name string, got, want []Call) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: calls\n%v\nwant\n%v", name, got, want)
+	}
+}
+
+func translate(x, y, z float64) glm.Mat4d {
+	return glm.Mat4d{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, x, y, z, 1}
+}
+
+func floatMatrix(m glm.Mat4d) []gl.Float {
+	f := make([]gl.Float, len(m))
+	for i, v := range m {
+		f[i] = gl.Float(v)
+	}
+	return f
+}
+
+type testBindings struct {
+	Buffer    gl.Buffer
+	Texture   gl.Texture
+	VAO       gl.VertexArrayObject
+	Vertex    gl.VertexShader
+	Fragment  gl.FragmentShader
+	Program   gl.Program
+	Untouched int
+}
+
+func TestBind(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	var bindings testBindings
+	Bind(&bindings)
+	checkCalls(t, "Bind", r.Calls, []Call{
+		call("GenBuffer"),
+		call("GenTexture"),
+		call("GenVertexArray"),
+		call("CreateShader", gl.Enum(gl.VERTEX_SHADER)),
+		call("CreateShader", gl.Enum(gl.FRAGMENT_SHADER)),
+		call("CreateProgram"),
+	})
+	want := testBindings{1, 2, 3, 4, 5, 6, 0}
+	if bindings != want {
+		t.Errorf("bound %+v, want %+v", bindings, want)
+	}
+	if len(r.Live) != 6 {
+		t.Errorf("%d live objects, want 6", len(r.Live))
+	}
+}
+
+type testLocations struct {
+	ModelView gl.UniformLocation   `gl:"modelview"`
+	Missing   gl.UniformLocation   `gl:"missing"`
+	Position  gl.AttributeLocation `gl:"position"`
+	Untagged  gl.UniformLocation
+}
+
+func TestBindProgramLocations(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.ActiveUniforms = []ProgramVariable{{"modelview", gl.FLOAT_MAT4, 1, 3}}
+	r.ActiveAttributes = []ProgramVariable{{"position", gl.FLOAT_VEC3, 1, 2}}
+	var locations testLocations
+	BindProgramLocations(7, &locations)
+	checkCalls(t, "BindProgramLocations", r.Calls, []Call{
+		call("GetUniformLocation", gl.Program(7), "modelview"),
+		call("GetError"),
+		call("GetUniformLocation", gl.Program(7), "missing"),
+		call("GetError"),
+		call("GetAttribLocation", gl.Program(7), "position"),
+		call("GetError"),
+	})
+	want := testLocations{3, -1, 2, 0}
+	if locations != want {
+		t.Errorf("bound %+v, want %+v", locations, want)
+	}
+}
+
+func TestNewGeometry(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	geometry := NewGeometry("triangle", []float64{0, 0, 0, 1, 0, 0, 0, 1, 0}, []float64{0, 0, 1, 0, 0, 1, 0, 0, 1}, []*DrawElements{NewDrawElements([]int16{0, 1, 2}, gl.TRIANGLES)})
+	names := []string{
+		"GenBuffer", "BindBuffer", "BufferData",
+		"GenBuffer", "BindBuffer", "BufferData",
+		"GenBuffer", "BindBuffer", "BufferData",
+	}
+	if !reflect.DeepEqual(r.Names(), names) {
+		t.Errorf("calls %v, want %v", r.Names(), names)
+	}
+	if geometry.VertexBuffer != 2 || geometry.NormalBuffer != 3 || len(geometry.Streams) != 2 {
+		t.Errorf("geometry %+v", geometry)
+	}
+	if elements := geometry.Elements[0]; *elements != (DrawElements{1, gl.TRIANGLES, 3, gl.UNSIGNED_SHORT}) {
+		t.Errorf("elements %+v", elements)
+	}
+	if n := len(r.BufferContents[1]); n != 6 {
+		t.Errorf("index buffer holds %d bytes, want 6", n)
+	}
+	geometry.Delete()
+	if len(r.Live) != 0 {
+		t.Errorf("leaked %v", r.Live)
+	}
+}
+
+func TestDrawModel(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	geometry := NewGeometry("triangle", []float64{0, 0, 0, 1, 0, 0, 0, 1, 0}, []float64{0, 0, 1, 0, 0, 1, 0, 0, 1}, []*DrawElements{NewDrawElements([]int16{0, 1, 2}, gl.TRIANGLES)})
+	child := NewModel("child", nil, []*Geometry{geometry}, translate(0, 0, 5))
+	root := NewModel("root", []*Model{child}, nil, translate(1, 2, 3))
+	r.Reset()
+	DrawModel(translate(0, 0, 0), root, 4, 0, 9)
+	checkCalls(t, "DrawModel", r.Calls, []Call{
+		call("UniformMatrix4fv", gl.UniformLocation(4), gl.Sizei(1), gl.Boolean(gl.FALSE), floatMatrix(translate(1, 2, 3))),
+		call("UniformMatrix4fv", gl.UniformLocation(4), gl.Sizei(1), gl.Boolean(gl.FALSE), floatMatrix(translate(1, 2, 8))),
+		call("BindVertexArray", gl.VertexArrayObject(9)),
+		call("BindBuffer", gl.Enum(gl.ARRAY_BUFFER), gl.Buffer(2)),
+		call("VertexAttribPointer", gl.AttributeLocation(0), gl.Int(3), gl.Enum(gl.FLOAT), gl.Boolean(gl.FALSE), gl.Sizei(12), BufferOffset(0)),
+		call("EnableVertexAttribArray", gl.AttributeLocation(0)),
+		call("BindBuffer", gl.Enum(gl.ARRAY_BUFFER), gl.Buffer(3)),
+		call("BindBuffer", gl.Enum(gl.ELEMENT_ARRAY_BUFFER), gl.Buffer(1)),
+		call("DrawElements", gl.Enum(gl.TRIANGLES), gl.Sizei(3), gl.Enum(gl.UNSIGNED_SHORT), gl.Pointer(nil)),
+		call("GetError"),
+		call("DisableVertexAttribArray", gl.AttributeLocation(0)),
+	})
+}
+
+func TestDrawGeometryAttributesBindsNormals(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	geometry := NewGeometry("triangle", []float64{0, 0, 0, 1, 0, 0, 0, 1, 0}, []float64{0, 0, 1, 0, 0, 1, 0, 0, 1}, nil)
+	r.Reset()
+	DrawGeometryAttributes(geometry, map[string]gl.AttributeLocation{"position": 0, "normal": 1}, 9)
+	checkCalls(t, "DrawGeometryAttributes", r.Calls, []Call{
+		call("BindVertexArray", gl.VertexArrayObject(9)),
+		call("BindBuffer", gl.Enum(gl.ARRAY_BUFFER), gl.Buffer(1)),
+		call("VertexAttribPointer", gl.AttributeLocation(0), gl.Int(3), gl.Enum(gl.FLOAT), gl.Boolean(gl.FALSE), gl.Sizei(12), BufferOffset(0)),
+		call("EnableVertexAttribArray", gl.AttributeLocation(0)),
+		call("BindBuffer", gl.Enum(gl.ARRAY_BUFFER), gl.Buffer(2)),
+		call("VertexAttribPointer", gl.AttributeLocation(1), gl.Int(3), gl.Enum(gl.FLOAT), gl.Boolean(gl.FALSE), gl.Sizei(12), BufferOffset(0)),
+		call("EnableVertexAttribArray", gl.AttributeLocation(1)),
+		call("DisableVertexAttribArray", gl.AttributeLocation(0)),
+		call("DisableVertexAttribArray", gl.AttributeLocation(1)),
+	})
+}
+
+func TestStencilOp(t *testing.T) {
+	all := ^gl.Uint(0)
+	tests := []struct {
+		name  string
+		apply func(s *StencilOp)
+		calls []Call
+	}{
+		{"enable", func(s *StencilOp) { s.Enable() }, []Call{
+			call("Enable", gl.Enum(gl.STENCIL_TEST)),
+			call("ColorMask", gl.Boolean(gl.TRUE), gl.Boolean(gl.TRUE), gl.Boolean(gl.TRUE), gl.Boolean(gl.TRUE)),
+			call("StencilFunc", gl.Enum(gl.EQUAL), gl.Int(0), all),
+			call("StencilOp", gl.Enum(gl.KEEP), gl.Enum(gl.KEEP), gl.Enum(gl.KEEP)),
+			call("Enable", gl.Enum(gl.DEPTH_TEST)),
+			call("DepthMask", gl.Boolean(gl.TRUE)),
+			call("DepthFunc", gl.Enum(gl.LESS)),
+		}},
+		{"disable", func(s *StencilOp) { s.Disable() }, []Call{
+			call("Disable", gl.Enum(gl.STENCIL_TEST)),
+			call("ColorMask", gl.Boolean(gl.TRUE), gl.Boolean(gl.TRUE), gl.Boolean(gl.TRUE), gl.Boolean(gl.TRUE)),
+			call("Enable", gl.Enum(gl.DEPTH_TEST)),
+			call("DepthMask", gl.Boolean(gl.TRUE)),
+			call("DepthFunc", gl.Enum(gl.LESS)),
+		}},
+		{"write mask", func(s *StencilOp) { s.NoDraw().NoDepthMask().Unmask(2).Replace() }, []Call{
+			call("ColorMask", gl.Boolean(gl.FALSE), gl.Boolean(gl.FALSE), gl.Boolean(gl.FALSE), gl.Boolean(gl.FALSE)),
+			call("DepthMask", gl.Boolean(gl.FALSE)),
+			call("StencilFunc", gl.Enum(gl.ALWAYS), gl.Int(2), all),
+			call("StencilOp", gl.Enum(gl.KEEP), gl.Enum(gl.KEEP), gl.Enum(gl.REPLACE)),
+		}},
+		{"depth", func(s *StencilOp) { s.NoDepth().DepthLE().DepthAlways() }, []Call{
+			call("Disable", gl.Enum(gl.DEPTH_TEST)),
+			call("DepthFunc", gl.Enum(gl.LEQUAL)),
+			call("DepthFunc", gl.Enum(gl.ALWAYS)),
+		}},
+		{"counting", func(s *StencilOp) { s.Mask(1).Increment().Decrement().Keep() }, []Call{
+			call("StencilFunc", gl.Enum(gl.EQUAL), gl.Int(1), all),
+			call("StencilOp", gl.Enum(gl.KEEP), gl.Enum(gl.KEEP), gl.Enum(gl.INCR)),
+			call("StencilOp", gl.Enum(gl.KEEP), gl.Enum(gl.KEEP), gl.Enum(gl.DECR)),
+			call("StencilOp", gl.Enum(gl.KEEP), gl.Enum(gl.KEEP), gl.Enum(gl.KEEP)),
+		}},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		previous := SetBackend(r)
+		test.apply(&Stencil)
+		SetBackend(previous)
+		checkCalls(t, test.name, r.Calls, test.calls)
+	}
+}
+
+func TestIndexType(t *testing.T) {
+	tests := []struct {
+		vertexCount int
+		indexType   gl.Enum
+	}{
+		{0, gl.UNSIGNED_BYTE},
+		{256, gl.UNSIGNED_BYTE},
+		{257, gl.UNSIGNED_SHORT},
+		{65536, gl.UNSIGNED_SHORT},
+		{65537, gl.UNSIGNED_INT},
+	}
+	for _, test := range tests {
+		if got := IndexType(test.vertexCount); got != test.indexType {
+			t.Errorf("%d vertices: index type 0x%X, want 0x%X", test.vertexCount, got, test.indexType)
+		}
+	}
+}
+
+func TestPackIndices(t *testing.T) {
+	tests := []struct {
+		indices   []uint32
+		indexType gl.Enum
+		want      interface{}
+	}{
+		{[]uint32{0, 255}, gl.UNSIGNED_BYTE, []uint8{0, 255}},
+		{[]uint32{256, 65535}, gl.UNSIGNED_SHORT, []uint16{256, 65535}},
+		{[]uint32{65535, 65536}, gl.UNSIGNED_INT, []uint32{65535, 65536}},
+	}
+	for _, test := range tests {
+		if got := PackIndices(test.indices, test.indexType); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("0x%X: packed %v, want %v", test.indexType, got, test.want)
+		}
+	}
+}
+
+func TestPrimitiveIndices(t *testing.T) {
+	tests := []struct {
+		name      string
+		geometry  func() *Geometry
+		indexType gl.Enum
+	}{
+		{"wheel", Wheel, gl.UNSIGNED_BYTE},
+		{"sphere", Sphere, gl.UNSIGNED_SHORT},
+		{"small grid", func() *Geometry { return Grid(4) }, gl.UNSIGNED_BYTE},
+		// 65540 vertices overflow 16 bit indices.
+		{"large grid", func() *Geometry { return Grid(8192) }, gl.UNSIGNED_INT},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		previous := SetBackend(r)
+		geometry := test.geometry()
+		SetBackend(previous)
+		vertexCount := len(r.BufferContents[geometry.VertexBuffer]) / 12
+		for i, elements := range geometry.Elements {
+			if elements.IndexType != test.indexType {
+				t.Errorf("%s: elements %d index type 0x%X, want 0x%X", test.name, i, elements.IndexType, test.indexType)
+			}
+			data := r.BufferContents[elements.Buffer]
+			size := ComponentSize(elements.IndexType)
+			if len(data) != elements.Count*size {
+				t.Errorf("%s: elements %d hold %d bytes for %d indices", test.name, i, len(data), elements.Count)
+				continue
+			}
+			for j := 0; j < elements.Count; j++ {
+				index := int(data[j])
+				switch size {
+				case 2:
+					index = int(nativeOrder.Uint16(data[j*2:]))
+				case 4:
+					index = int(nativeOrder.Uint32(data[j*4:]))
+				}
+				if index >= vertexCount {
+					t.Errorf("%s: elements %d index %d is %d, past %d vertices", test.name, i, j, index, vertexCount)
+					break
+				}
+			}
+		}
+	}
+}
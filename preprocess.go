@@ -0,0 +1,231 @@
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CoreGLSLVersion   = 150
+	LegacyGLSLVersion = 120
+)
+
+// ShaderOptions select the GLSL dialect and defines. Legacy must match the
+// context: pass the legacy flag given to gameloop.CreateWindow, which the
+// gameloop package also reports from LegacyContext.
+type ShaderOptions struct {
+	Legacy  bool
+	Version int
+	Defines map[string]string
+}
+
+var DefaultShaderOptions = ShaderOptions{}
+
+func (options ShaderOptions) WithDefines(defines ...map[string]string) ShaderOptions {
+	merged := make(map[string]string, len(options.Defines))
+	for name, value := range options.Defines {
+		merged[name] = value
+	}
+	for _, d := range defines {
+		for name, value := range d {
+			merged[name] = value
+		}
+	}
+	options.Defines = merged
+	return options
+}
+
+type ShaderSource struct {
+	Source  string
+	Version int
+	Files   []string
+}
+
+func (s *ShaderSource) FileName(index int) string {
+	if index >= 0 && index < len(s.Files) {
+		return s.Files[index]
+	}
+	return ""
+}
+
+type ReadFileFunc func(filename string) ([]byte, error)
+
+var (
+	versionDirective = regexp.MustCompile(`^\s*#\s*version\s+(\d+)(\s+\w+)?\s*$`)
+	includeDirective = regexp.MustCompile(`^\s*#\s*include\s+(?:"([^"]+)"|<([^>]+)>)\s*$`)
+)
+
+func PreprocessShader(filename string, options ShaderOptions) (*ShaderSource, error) {
+	return PreprocessShaderWith(ioutil.ReadFile, filename, options)
+}
+
+func PreprocessShaderWith(readFile ReadFileFunc, filename string, options ShaderOptions) (*ShaderSource, error) {
+	p := &preprocessor{
+		readFile: readFile,
+		source:   &ShaderSource{},
+	}
+	lines, err := p.read(filename)
+	if err != nil {
+		return nil, err
+	}
+	versionLine, declared := findVersion(lines)
+	p.source.Version = selectVersion(declared, options)
+
+	var out strings.Builder
+	header := p.header(options)
+	if versionLine < 0 {
+		out.WriteString(header)
+		out.WriteString(p.lineDirective(1, 0))
+	}
+	p.stack = []string{filepath.Clean(filename)}
+	p.source.Files = append(p.source.Files, filename)
+	for i, line := range lines {
+		if i == versionLine {
+			out.WriteString(header)
+			out.WriteString(p.lineDirective(i+2, 0))
+			continue
+		}
+		err := p.expandLine(&out, filename, 0, i, line)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.source.Source = out.String()
+	return p.source, nil
+}
+
+func GLSLVersion(legacy bool) int {
+	if legacy {
+		return LegacyGLSLVersion
+	}
+	return CoreGLSLVersion
+}
+
+type preprocessor struct {
+	readFile ReadFileFunc
+	source   *ShaderSource
+	stack    []string
+}
+
+func (p *preprocessor) read(filename string) ([]string, error) {
+	bytes, err := p.readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	source := strings.Replace(string(bytes), "\r\n", "\n", -1)
+	return strings.Split(strings.TrimSuffix(source, "\n"), "\n"), nil
+}
+
+func (p *preprocessor) header(options ShaderOptions) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#version %d", p.source.Version)
+	if p.source.Version >= CoreGLSLVersion && !options.Legacy {
+		b.WriteString(" core")
+	}
+	b.WriteString("\n")
+	if options.Legacy {
+		b.WriteString("#define GLUTIL_LEGACY 1\n")
+	} else {
+		b.WriteString("#define GLUTIL_CORE 1\n")
+	}
+	names := make([]string, 0, len(options.Defines))
+	for name := range options.Defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := options.Defines[name]
+		if value == "" {
+			fmt.Fprintf(&b, "#define %s\n", name)
+		} else {
+			fmt.Fprintf(&b, "#define %s %s\n", name, value)
+		}
+	}
+	return b.String()
+}
+
+// lineDirective makes the line after the directive report as line next of the
+// given source string. Before GLSL 330 #line named the directive's own line.
+func (p *preprocessor) lineDirective(next, file int) string {
+	if p.source.Version < 330 {
+		next--
+	}
+	return fmt.Sprintf("#line %d %d\n", next, file)
+}
+
+func (p *preprocessor) expandLine(out *strings.Builder, filename string, file, i int, line string) error {
+	if file != 0 && versionDirective.MatchString(line) {
+		out.WriteString("\n")
+		return nil
+	}
+	match := includeDirective.FindStringSubmatch(line)
+	if match == nil {
+		out.WriteString(line)
+		out.WriteString("\n")
+		return nil
+	}
+	name := match[1]
+	if name == "" {
+		name = match[2]
+	}
+	path := filepath.Join(filepath.Dir(filename), name)
+	return p.include(out, path, file, i+1, fmt.Sprintf("%s:%d", filename, i+1))
+}
+
+func (p *preprocessor) include(out *strings.Builder, filename string, parent, parentLine int, from string) error {
+	clean := filepath.Clean(filename)
+	for _, f := range p.stack {
+		if f == clean {
+			cycle := append(append([]string{}, p.stack...), clean)
+			return fmt.Errorf("%s: include cycle: %s", from, strings.Join(cycle, " -> "))
+		}
+	}
+	lines, err := p.read(filename)
+	if err != nil {
+		return fmt.Errorf("%s: %v", from, err)
+	}
+	file := len(p.source.Files)
+	p.source.Files = append(p.source.Files, filename)
+	p.stack = append(p.stack, clean)
+	out.WriteString(p.lineDirective(1, file))
+	for i, line := range lines {
+		err := p.expandLine(out, filename, file, i, line)
+		if err != nil {
+			return err
+		}
+	}
+	p.stack = p.stack[:len(p.stack)-1]
+	out.WriteString(p.lineDirective(parentLine+1, parent))
+	return nil
+}
+
+func findVersion(lines []string) (int, int) {
+	for i, line := range lines {
+		match := versionDirective.FindStringSubmatch(line)
+		if match != nil {
+			version, err := strconv.Atoi(match[1])
+			if err == nil {
+				return i, version
+			}
+		}
+	}
+	return -1, 0
+}
+
+func selectVersion(declared int, options ShaderOptions) int {
+	if options.Version != 0 {
+		return options.Version
+	}
+	if options.Legacy {
+		return LegacyGLSLVersion
+	}
+	if declared > CoreGLSLVersion {
+		return declared
+	}
+	return CoreGLSLVersion
+}
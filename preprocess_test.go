@@ -0,0 +1,100 @@
+package render
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func memoryFiles(files map[string]string) ReadFileFunc {
+	return func(filename string) ([]byte, error) {
+		source, ok := files[filename]
+		if !ok {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+		}
+		return []byte(source), nil
+	}
+}
+
+func TestPreprocessShader(t *testing.T) {
+	files := map[string]string{
+		"main.frag":       "#version 330\n#include \"lib/light.glsl\"\nvoid main() {}\n",
+		"lib/light.glsl":  "#include <common.glsl>\nfloat light;\n",
+		"lib/common.glsl": "#version 330\nfloat common;\n",
+		"plain.vert":      "void main() {}\n",
+	}
+	tests := []struct {
+		name     string
+		filename string
+		options  ShaderOptions
+		source   string
+		files    []string
+	}{
+		{
+			"includes", "main.frag", ShaderOptions{},
+			"#version 330 core\n#define GLUTIL_CORE 1\n#line 2 0\n" +
+				"#line 1 1\n" +
+				"#line 1 2\n\nfloat common;\n#line 2 1\n" +
+				"float light;\n#line 3 0\n" +
+				"void main() {}\n",
+			[]string{"main.frag", "lib/light.glsl", "lib/common.glsl"},
+		},
+		{
+			"legacy includes", "main.frag", ShaderOptions{Legacy: true},
+			"#version 120\n#define GLUTIL_LEGACY 1\n#line 1 0\n" +
+				"#line 0 1\n" +
+				"#line 0 2\n\nfloat common;\n#line 1 1\n" +
+				"float light;\n#line 2 0\n" +
+				"void main() {}\n",
+			[]string{"main.frag", "lib/light.glsl", "lib/common.glsl"},
+		},
+		{
+			"no version", "plain.vert", ShaderOptions{Defines: map[string]string{"B": "2", "A": ""}},
+			"#version 150 core\n#define GLUTIL_CORE 1\n#define A\n#define B 2\n#line 0 0\nvoid main() {}\n",
+			[]string{"plain.vert"},
+		},
+		{
+			"explicit version", "plain.vert", ShaderOptions{Version: 410},
+			"#version 410 core\n#define GLUTIL_CORE 1\n#line 1 0\nvoid main() {}\n",
+			[]string{"plain.vert"},
+		},
+	}
+	for _, test := range tests {
+		source, err := PreprocessShaderWith(memoryFiles(files), test.filename, test.options)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if source.Source != test.source {
+			t.Errorf("%s: source\n%s\nwant\n%s", test.name, source.Source, test.source)
+		}
+		if strings.Join(source.Files, ",") != strings.Join(test.files, ",") {
+			t.Errorf("%s: files %v, want %v", test.name, source.Files, test.files)
+		}
+	}
+}
+
+func TestPreprocessShaderErrors(t *testing.T) {
+	files := map[string]string{
+		"cycle/a.glsl":      "#include \"b.glsl\"\n",
+		"cycle/b.glsl":      "#include \"a.glsl\"\n",
+		"cycle/main.frag":   "#version 150\n#include \"a.glsl\"\n",
+		"self.glsl":         "#include \"self.glsl\"\n",
+		"missing/main.vert": "\n#include \"none.glsl\"\n",
+	}
+	tests := []struct {
+		filename string
+		err      string
+	}{
+		{"cycle/main.frag", "cycle/b.glsl:1: include cycle: cycle/main.frag -> cycle/a.glsl -> cycle/b.glsl -> cycle/a.glsl"},
+		{"self.glsl", "self.glsl:1: include cycle: self.glsl -> self.glsl"},
+		{"missing/main.vert", "missing/main.vert:2: open missing/none.glsl: file does not exist"},
+		{"none.vert", "open none.vert: file does not exist"},
+	}
+	for _, test := range tests {
+		_, err := PreprocessShaderWith(memoryFiles(files), test.filename, ShaderOptions{})
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: error %v, want %s", test.filename, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"os"
+	"sort"
+	"strings"
+)
+
+type ShaderStage gl.Enum
+
+// The tessellation and compute enums are not exported by gl32 since they
+// need a 4.x context.
+const (
+	VertexStage         ShaderStage = gl.VERTEX_SHADER
+	TessControlStage    ShaderStage = 0x8E88
+	TessEvaluationStage ShaderStage = 0x8E87
+	GeometryStage       ShaderStage = gl.GEOMETRY_SHADER
+	FragmentStage       ShaderStage = gl.FRAGMENT_SHADER
+	ComputeStage        ShaderStage = 0x91B9
+)
+
+func (stage ShaderStage) String() string {
+	switch stage {
+	case VertexStage:
+		return "vertex"
+	case TessControlStage:
+		return "tess control"
+	case TessEvaluationStage:
+		return "tess evaluation"
+	case GeometryStage:
+		return "geometry"
+	case FragmentStage:
+		return "fragment"
+	case ComputeStage:
+		return "compute"
+	}
+	return fmt.Sprintf("stage 0x%X", gl.Enum(stage))
+}
+
+type ProgramStages struct {
+	Vertex         string `json:"vertex,omitempty"`
+	TessControl    string `json:"tessControl,omitempty"`
+	TessEvaluation string `json:"tessEvaluation,omitempty"`
+	Geometry       string `json:"geometry,omitempty"`
+	Fragment       string `json:"fragment,omitempty"`
+	Compute        string `json:"compute,omitempty"`
+}
+
+type stageFile struct {
+	Stage    ShaderStage
+	Filename string
+}
+
+func (stages ProgramStages) files() []stageFile {
+	all := []stageFile{
+		{VertexStage, stages.Vertex},
+		{TessControlStage, stages.TessControl},
+		{TessEvaluationStage, stages.TessEvaluation},
+		{GeometryStage, stages.Geometry},
+		{FragmentStage, stages.Fragment},
+		{ComputeStage, stages.Compute},
+	}
+	files := make([]stageFile, 0, len(all))
+	for _, f := range all {
+		if f.Filename != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (stages ProgramStages) Validate() error {
+	files := stages.files()
+	if len(files) == 0 {
+		return errors.New("program has no shader stages")
+	}
+	if stages.Compute != "" {
+		if len(files) > 1 {
+			return errors.New("compute shaders can not be linked with other stages")
+		}
+		return nil
+	}
+	if stages.Vertex == "" {
+		return errors.New("program has no vertex stage")
+	}
+	if stages.TessControl != "" && stages.TessEvaluation == "" {
+		return errors.New("tess control stage requires a tess evaluation stage")
+	}
+	return nil
+}
+
+func (lib *ShaderLibrary) LoadShaderStage(stage ShaderStage, filename string, options ShaderOptions) (gl.Uint, error) {
+	key := shaderKey(stage, filename, options)
+	shader, ok := lib.Shaders[key]
+	if ok {
+		return shader, nil
+	}
+	shader = CreateShader(gl.Enum(stage))
+	err := LoadShaderWith(lib.readFile(), shader, filename, options)
+	if err != nil {
+		DeleteShader(shader)
+		return 0, err
+	}
+	lib.Shaders[key] = shader
+	return shader, nil
+}
+
+func (lib *ShaderLibrary) BuildProgram(tag string, stages ProgramStages) error {
+	return lib.BuildProgramWithOptions(tag, stages, lib.Options)
+}
+
+func (lib *ShaderLibrary) BuildProgramWithOptions(tag string, stages ProgramStages, options ShaderOptions) error {
+	if _, ok := lib.Programs[tag]; ok {
+		return errors.New("program: '" + tag + "' already defined")
+	}
+	err := stages.Validate()
+	if err != nil {
+		return fmt.Errorf("program '%s': %v", tag, err)
+	}
+	cacheKey := ""
+	if lib.BinaryCache != nil {
+		cacheKey, err = lib.BinaryCache.KeyWith(lib.readFile(), stages, options)
+		if err != nil {
+			return err
+		}
+		program := CreateProgram()
+		if lib.BinaryCache.Load(program, cacheKey) {
+			lib.Programs[tag] = program
+			return nil
+		}
+		DeleteProgram(program)
+	}
+	files := stages.files()
+	shaders := make([]gl.Uint, 0, len(files))
+	for _, f := range files {
+		shader, err := lib.LoadShaderStage(f.Stage, f.Filename, options)
+		if err != nil {
+			return err
+		}
+		shaders = append(shaders, shader)
+	}
+	program := CreateProgram()
+	if lib.BinaryCache != nil {
+		lib.BinaryCache.Driver.SetRetrievable(program)
+	}
+	err = LinkShaders(program, shaders...)
+	if err != nil {
+		return fmt.Errorf("program '%s': %v", tag, err)
+	}
+	for _, shader := range shaders {
+		Backend.DetachShader(program, shader)
+	}
+	if lib.BinaryCache != nil {
+		err = lib.BinaryCache.Store(program, cacheKey)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "program '"+tag+"': binary not cached:", err)
+		}
+	}
+	lib.Programs[tag] = program
+	return nil
+}
+
+func (lib *ShaderLibrary) LoadProgramStages(tag string, stages ProgramStages) {
+	err := lib.BuildProgram(tag, stages)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func shaderKey(stage ShaderStage, filename string, options ShaderOptions) string {
+	parts := []string{fmt.Sprintf("%d:%s:%t:%d", gl.Enum(stage), filename, options.Legacy, options.Version)}
+	for name, value := range options.Defines {
+		parts = append(parts, name+"="+value)
+	}
+	sort.Strings(parts[1:])
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProgramStagesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages ProgramStages
+		err    string
+	}{
+		{"vertex and fragment", ProgramStages{Vertex: "a.vert", Fragment: "a.frag"}, ""},
+		{"depth only", ProgramStages{Vertex: "a.vert"}, ""},
+		{"tessellated", ProgramStages{Vertex: "a.vert", TessControl: "a.tesc", TessEvaluation: "a.tese", Fragment: "a.frag"}, ""},
+		{"compute", ProgramStages{Compute: "a.comp"}, ""},
+		{"empty", ProgramStages{}, "no shader stages"},
+		{"fragment only", ProgramStages{Fragment: "a.frag"}, "no vertex stage"},
+		{"compute with vertex", ProgramStages{Vertex: "a.vert", Compute: "a.comp"}, "compute shaders"},
+		{"compute with fragment", ProgramStages{Fragment: "a.frag", Compute: "a.comp"}, "compute shaders"},
+		{"tess control alone", ProgramStages{Vertex: "a.vert", TessControl: "a.tesc", Fragment: "a.frag"}, "tess evaluation"},
+	}
+	for _, test := range tests {
+		err := test.stages.Validate()
+		if (err == nil) != (test.err == "") || err != nil && !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestProgramStagesFiles(t *testing.T) {
+	stages := ProgramStages{Fragment: "a.frag", Geometry: "a.geom", Vertex: "a.vert", TessEvaluation: "a.tese"}
+	want := []stageFile{
+		{VertexStage, "a.vert"},
+		{TessEvaluationStage, "a.tese"},
+		{GeometryStage, "a.geom"},
+		{FragmentStage, "a.frag"},
+	}
+	if got := stages.files(); !reflect.DeepEqual(got, want) {
+		t.Errorf("files %v, want %v", got, want)
+	}
+	if got := (ProgramStages{}).files(); len(got) != 0 {
+		t.Errorf("empty stages have files %v", got)
+	}
+}
+
+func TestShaderKey(t *testing.T) {
+	options := ShaderOptions{Version: 330, Defines: map[string]string{"A": "1", "B": "2", "C": "3"}}
+	key := shaderKey(VertexStage, "a.vert", options)
+	for i := 0; i < 20; i++ {
+		reordered := ShaderOptions{Version: 330, Defines: map[string]string{"C": "3", "A": "1", "B": "2"}}
+		if got := shaderKey(VertexStage, "a.vert", reordered); got != key {
+			t.Fatalf("key %q changed to %q", key, got)
+		}
+	}
+	different := []struct {
+		name     string
+		stage    ShaderStage
+		filename string
+		options  ShaderOptions
+	}{
+		{"stage", FragmentStage, "a.vert", options},
+		{"filename", VertexStage, "b.vert", options},
+		{"version", VertexStage, "a.vert", ShaderOptions{Version: 410, Defines: options.Defines}},
+		{"legacy", VertexStage, "a.vert", ShaderOptions{Version: 330, Legacy: true, Defines: options.Defines}},
+		{"define value", VertexStage, "a.vert", ShaderOptions{Version: 330, Defines: map[string]string{"A": "1", "B": "2", "C": "4"}}},
+		{"define missing", VertexStage, "a.vert", ShaderOptions{Version: 330, Defines: map[string]string{"A": "1", "B": "2"}}},
+	}
+	for _, test := range different {
+		if got := shaderKey(test.stage, test.filename, test.options); got == key {
+			t.Errorf("%s: key unchanged %q", test.name, got)
+		}
+	}
+}
@@ -0,0 +1,682 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"strings"
+	"unsafe"
+)
+
+type Call struct {
+	Name string
+	Args []interface{}
+}
+
+func (c Call) String() string {
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = fmt.Sprint(arg)
+	}
+	return c.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// RecordingBackend logs every call and emulates enough state (object ids,
+// buffer contents, compile and link status, active program variables) for
+// pipeline code to be exercised without a GL context.
+type RecordingBackend struct {
+	Calls []Call
+
+	CompileLog          string
+	CompileStatus       bool
+	LinkLog             string
+	LinkStatus          bool
+	Errors              []gl.Enum
+	SyncResults         []gl.Enum
+	FramebufferStatus   gl.Enum
+	ActiveUniforms      []ProgramVariable
+	ActiveAttributes    []ProgramVariable
+	ActiveUniformBlocks []UniformBlock
+
+	Live           map[Resource]bool
+	Sources        map[gl.Uint][]string
+	BufferContents map[gl.Buffer][]byte
+	BoundBuffers   map[gl.Enum]gl.Buffer
+	BoundTextures  map[gl.Enum]gl.Texture
+	Capabilities   map[gl.Enum]bool
+
+	BoundFramebuffers map[gl.Enum]gl.Framebuffer
+
+	// PixelSource supplies the tightly packed pixels ReadPixels writes. When
+	// nil the destination is left untouched.
+	PixelSource func(x, y, width, height int, format, xtype gl.Enum) []byte
+
+	// MajorVersion, MinorVersion and Extensions describe the emulated
+	// context. The default is a bare 3.2 core context.
+	MajorVersion int
+	MinorVersion int
+	Extensions   []string
+	// Anisotropy is the emulated MAX_TEXTURE_MAX_ANISOTROPY_EXT.
+	Anisotropy gl.Float
+
+	nextId gl.Uint
+	fences map[gl.Sync]*int
+}
+
+func NewRecordingBackend() *RecordingBackend {
+	return &RecordingBackend{
+		CompileStatus:  true,
+		LinkStatus:     true,
+		MajorVersion:   3,
+		MinorVersion:   2,
+		Live:           make(map[Resource]bool),
+		Sources:        make(map[gl.Uint][]string),
+		BufferContents: make(map[gl.Buffer][]byte),
+		BoundBuffers:   make(map[gl.Enum]gl.Buffer),
+		BoundTextures:  make(map[gl.Enum]gl.Texture),
+		Capabilities:   make(map[gl.Enum]bool),
+		fences:         make(map[gl.Sync]*int),
+
+		BoundFramebuffers: make(map[gl.Enum]gl.Framebuffer),
+	}
+}
+
+func (r *RecordingBackend) record(name string, args ...interface{}) {
+	r.Calls = append(r.Calls, Call{name, args})
+}
+
+func (r *RecordingBackend) Reset() {
+	r.Calls = nil
+}
+
+func (r *RecordingBackend) Count(name string) int {
+	n := 0
+	for _, call := range r.Calls {
+		if call.Name == name {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *RecordingBackend) Names() []string {
+	names := make([]string, len(r.Calls))
+	for i, call := range r.Calls {
+		names[i] = call.Name
+	}
+	return names
+}
+
+func (r *RecordingBackend) create(kind ResourceKind) gl.Uint {
+	r.nextId++
+	r.Live[Resource{kind, r.nextId}] = true
+	return r.nextId
+}
+
+func (r *RecordingBackend) delete(kind ResourceKind, id gl.Uint) {
+	delete(r.Live, Resource{kind, id})
+}
+
+func (r *RecordingBackend) bytes(data gl.Pointer, size int) []byte {
+	if data == nil || size <= 0 {
+		return make([]byte, size)
+	}
+	return append([]byte{}, unsafe.Slice((*byte)(data), size)...)
+}
+
+func (r *RecordingBackend) CreateProgram() gl.Program {
+	r.record("CreateProgram")
+	return gl.Program(r.create(ProgramResource))
+}
+
+func (r *RecordingBackend) CreateShader(shaderType gl.Enum) gl.Uint {
+	r.record("CreateShader", shaderType)
+	return r.create(ShaderResource)
+}
+
+func (r *RecordingBackend) GenBuffer() gl.Buffer {
+	r.record("GenBuffer")
+	return gl.Buffer(r.create(BufferResource))
+}
+
+func (r *RecordingBackend) GenTexture() gl.Texture {
+	r.record("GenTexture")
+	return gl.Texture(r.create(TextureResource))
+}
+
+func (r *RecordingBackend) GenVertexArray() gl.VertexArrayObject {
+	r.record("GenVertexArray")
+	return gl.VertexArrayObject(r.create(VertexArrayResource))
+}
+
+func (r *RecordingBackend) GenFramebuffer() gl.Framebuffer {
+	r.record("GenFramebuffer")
+	return gl.Framebuffer(r.create(FramebufferResource))
+}
+
+func (r *RecordingBackend) GenRenderbuffer() gl.Renderbuffer {
+	r.record("GenRenderbuffer")
+	return gl.Renderbuffer(r.create(RenderbufferResource))
+}
+
+func (r *RecordingBackend) DeleteFramebuffer(framebuffer gl.Framebuffer) {
+	r.record("DeleteFramebuffer", framebuffer)
+	r.delete(FramebufferResource, gl.Uint(framebuffer))
+}
+
+func (r *RecordingBackend) DeleteRenderbuffer(renderbuffer gl.Renderbuffer) {
+	r.record("DeleteRenderbuffer", renderbuffer)
+	r.delete(RenderbufferResource, gl.Uint(renderbuffer))
+}
+
+// BindFramebuffer tracks the draw and read bindings separately.
+func (r *RecordingBackend) BindFramebuffer(target gl.Enum, framebuffer gl.Framebuffer) {
+	r.record("BindFramebuffer", target, framebuffer)
+	if target != gl.READ_FRAMEBUFFER {
+		r.BoundFramebuffers[gl.DRAW_FRAMEBUFFER] = framebuffer
+	}
+	if target != gl.DRAW_FRAMEBUFFER {
+		r.BoundFramebuffers[gl.READ_FRAMEBUFFER] = framebuffer
+	}
+}
+
+// CheckFramebufferStatus reports FramebufferStatus, or complete if unset.
+func (r *RecordingBackend) CheckFramebufferStatus(target gl.Enum) gl.Enum {
+	r.record("CheckFramebufferStatus", target)
+	if r.FramebufferStatus != 0 {
+		return r.FramebufferStatus
+	}
+	return gl.FRAMEBUFFER_COMPLETE
+}
+
+func (r *RecordingBackend) DeleteBuffer(buffer gl.Buffer) {
+	r.record("DeleteBuffer", buffer)
+	r.delete(BufferResource, gl.Uint(buffer))
+	delete(r.BufferContents, buffer)
+}
+
+func (r *RecordingBackend) DeleteProgram(program gl.Program) {
+	r.record("DeleteProgram", program)
+	r.delete(ProgramResource, gl.Uint(program))
+}
+
+func (r *RecordingBackend) DeleteShader(shader gl.Uint) {
+	r.record("DeleteShader", shader)
+	r.delete(ShaderResource, shader)
+}
+
+func (r *RecordingBackend) DeleteTexture(texture gl.Texture) {
+	r.record("DeleteTexture", texture)
+	r.delete(TextureResource, gl.Uint(texture))
+}
+
+func (r *RecordingBackend) DeleteVertexArray(vao gl.VertexArrayObject) {
+	r.record("DeleteVertexArray", vao)
+	r.delete(VertexArrayResource, gl.Uint(vao))
+}
+
+func (r *RecordingBackend) Enable(capability gl.Enum) {
+	r.record("Enable", capability)
+	r.Capabilities[capability] = true
+}
+
+func (r *RecordingBackend) Disable(capability gl.Enum) {
+	r.record("Disable", capability)
+	r.Capabilities[capability] = false
+}
+
+func (r *RecordingBackend) BindBuffer(target gl.Enum, buffer gl.Buffer) {
+	r.record("BindBuffer", target, buffer)
+	r.BoundBuffers[target] = buffer
+}
+
+func (r *RecordingBackend) BindTexture(target gl.Enum, texture gl.Texture) {
+	r.record("BindTexture", target, texture)
+	r.BoundTextures[target] = texture
+}
+
+func (r *RecordingBackend) BufferData(target gl.Enum, size gl.Sizeiptr, data gl.Pointer, usage gl.Enum) {
+	r.record("BufferData", target, size, data, usage)
+	r.BufferContents[r.BoundBuffers[target]] = r.bytes(data, int(size))
+}
+
+func (r *RecordingBackend) BufferSubData(target gl.Enum, offset gl.Intptr, size gl.Sizeiptr, data gl.Pointer) {
+	r.record("BufferSubData", target, offset, size, data)
+	contents := r.BufferContents[r.BoundBuffers[target]]
+	if int(offset)+int(size) > len(contents) {
+		r.Errors = append(r.Errors, gl.INVALID_VALUE)
+		return
+	}
+	copy(contents[offset:], r.bytes(data, int(size)))
+}
+
+func (r *RecordingBackend) MapBufferRange(target gl.Enum, offset gl.Intptr, length gl.Sizeiptr, access gl.Bitfield) gl.Pointer {
+	r.record("MapBufferRange", target, offset, length, access)
+	contents := r.BufferContents[r.BoundBuffers[target]]
+	if int(offset)+int(length) > len(contents) || length == 0 {
+		r.Errors = append(r.Errors, gl.INVALID_VALUE)
+		return nil
+	}
+	return gl.Pointer(&contents[offset])
+}
+
+func (r *RecordingBackend) UnmapBuffer(target gl.Enum) gl.Boolean {
+	r.record("UnmapBuffer", target)
+	return gl.TRUE
+}
+
+func (r *RecordingBackend) FenceSync(condition gl.Enum, flags gl.Bitfield) gl.Sync {
+	r.record("FenceSync", condition, flags)
+	fence := new(int)
+	sync := gl.Sync(unsafe.Pointer(fence))
+	r.fences[sync] = fence
+	return sync
+}
+
+func (r *RecordingBackend) DeleteSync(sync gl.Sync) {
+	r.record("DeleteSync", sync)
+	delete(r.fences, sync)
+}
+
+// ClientWaitSync returns the queued SyncResults in turn, then reports the
+// fence as signaled.
+func (r *RecordingBackend) ClientWaitSync(sync gl.Sync, flags gl.Bitfield, timeout gl.Uint64) gl.Enum {
+	r.record("ClientWaitSync", sync, flags, timeout)
+	if len(r.SyncResults) == 0 {
+		return gl.ALREADY_SIGNALED
+	}
+	result := r.SyncResults[0]
+	r.SyncResults = r.SyncResults[1:]
+	return result
+}
+
+func (r *RecordingBackend) ShaderSource(shader gl.Uint, sources []string) {
+	r.record("ShaderSource", shader, sources)
+	r.Sources[shader] = sources
+}
+
+func (r *RecordingBackend) GetShaderiv(shader gl.Uint, pname gl.Enum, params *gl.Int) {
+	r.record("GetShaderiv", shader, pname, params)
+	switch pname {
+	case gl.COMPILE_STATUS:
+		*params = boolInt(r.CompileStatus)
+	case gl.INFO_LOG_LENGTH:
+		*params = gl.Int(len(r.CompileLog))
+	}
+}
+
+func (r *RecordingBackend) GetShaderInfoLog(shader gl.Uint) string {
+	r.record("GetShaderInfoLog", shader)
+	return r.CompileLog
+}
+
+func (r *RecordingBackend) GetProgramiv(program gl.Program, pname gl.Enum, params *gl.Int) {
+	r.record("GetProgramiv", program, pname, params)
+	switch pname {
+	case gl.LINK_STATUS:
+		*params = boolInt(r.LinkStatus)
+	case gl.INFO_LOG_LENGTH:
+		*params = gl.Int(len(r.LinkLog))
+	case gl.ACTIVE_UNIFORMS:
+		*params = gl.Int(len(r.ActiveUniforms))
+	case gl.ACTIVE_ATTRIBUTES:
+		*params = gl.Int(len(r.ActiveAttributes))
+	case gl.ACTIVE_UNIFORM_BLOCKS:
+		*params = gl.Int(len(r.ActiveUniformBlocks))
+	}
+}
+
+func (r *RecordingBackend) GetProgramInfoLog(program gl.Program) string {
+	r.record("GetProgramInfoLog", program)
+	return r.LinkLog
+}
+
+func (r *RecordingBackend) GetActiveUniform(program gl.Program, index gl.Uint) (string, gl.Int, gl.Enum) {
+	r.record("GetActiveUniform", program, index)
+	u := r.ActiveUniforms[index]
+	return u.Name, gl.Int(u.Size), u.Type
+}
+
+func (r *RecordingBackend) GetActiveAttrib(program gl.Program, index gl.Uint) (string, gl.Int, gl.Enum) {
+	r.record("GetActiveAttrib", program, index)
+	a := r.ActiveAttributes[index]
+	return a.Name, gl.Int(a.Size), a.Type
+}
+
+func (r *RecordingBackend) GetUniformLocation(program gl.Program, name string) gl.UniformLocation {
+	r.record("GetUniformLocation", program, name)
+	for _, u := range r.ActiveUniforms {
+		if variableName(u.Name) == name {
+			return gl.UniformLocation(u.Location)
+		}
+	}
+	return -1
+}
+
+func (r *RecordingBackend) GetAttribLocation(program gl.Program, name string) gl.AttributeLocation {
+	r.record("GetAttribLocation", program, name)
+	location := gl.Int(-1)
+	for _, a := range r.ActiveAttributes {
+		if variableName(a.Name) == name {
+			location = a.Location
+		}
+	}
+	return gl.AttributeLocation(location)
+}
+
+func (r *RecordingBackend) GetActiveUniformBlockName(program gl.Program, index gl.Uint) string {
+	r.record("GetActiveUniformBlockName", program, index)
+	return r.ActiveUniformBlocks[index].Name
+}
+
+func (r *RecordingBackend) GetActiveUniformBlockiv(program gl.Program, index gl.Uint, pname gl.Enum, params *gl.Int) {
+	r.record("GetActiveUniformBlockiv", program, index, pname, params)
+	block := r.ActiveUniformBlocks[index]
+	switch pname {
+	case gl.UNIFORM_BLOCK_DATA_SIZE:
+		*params = gl.Int(block.DataSize)
+	case gl.UNIFORM_BLOCK_BINDING:
+		*params = gl.Int(block.Binding)
+	}
+}
+
+func (r *RecordingBackend) GetUniformBlockIndex(program gl.Program, name string) gl.Uint {
+	r.record("GetUniformBlockIndex", program, name)
+	for i, block := range r.ActiveUniformBlocks {
+		if block.Name == name {
+			return gl.Uint(i)
+		}
+	}
+	return gl.INVALID_INDEX
+}
+
+func (r *RecordingBackend) UniformBlockBinding(program gl.Program, index, binding gl.Uint) {
+	r.record("UniformBlockBinding", program, index, binding)
+	if int(index) < len(r.ActiveUniformBlocks) {
+		r.ActiveUniformBlocks[index].Binding = binding
+	}
+}
+
+func (r *RecordingBackend) GetError() gl.Enum {
+	r.record("GetError")
+	if len(r.Errors) == 0 {
+		return gl.NO_ERROR
+	}
+	err := r.Errors[0]
+	r.Errors = r.Errors[1:]
+	return err
+}
+
+func (r *RecordingBackend) GetString(name gl.Enum) string {
+	r.record("GetString", name)
+	return "RecordingBackend"
+}
+
+func (r *RecordingBackend) GetStringi(name gl.Enum, index gl.Uint) string {
+	r.record("GetStringi", name, index)
+	if name == gl.EXTENSIONS && int(index) < len(r.Extensions) {
+		return r.Extensions[index]
+	}
+	return ""
+}
+
+func (r *RecordingBackend) GetFloatv(pname gl.Enum, params *gl.Float) {
+	r.record("GetFloatv", pname)
+	if pname == MAX_TEXTURE_MAX_ANISOTROPY_EXT {
+		*params = r.Anisotropy
+	}
+}
+
+func (r *RecordingBackend) GetIntegerv(pname gl.Enum, params *gl.Int) {
+	r.record("GetIntegerv", pname)
+	switch pname {
+	case gl.MAJOR_VERSION:
+		*params = gl.Int(r.MajorVersion)
+	case gl.MINOR_VERSION:
+		*params = gl.Int(r.MinorVersion)
+	case gl.NUM_EXTENSIONS:
+		*params = gl.Int(len(r.Extensions))
+	}
+}
+
+// UniformMatrix3fv records a copy of the matrices rather than the pointer.
+func (r *RecordingBackend) UniformMatrix3fv(location gl.UniformLocation, count gl.Sizei, transpose gl.Boolean, value *gl.Float) {
+	r.record("UniformMatrix3fv", location, count, transpose, floats(value, 9*int(count)))
+}
+
+// UniformMatrix4fv records a copy of the matrices rather than the pointer.
+func (r *RecordingBackend) UniformMatrix4fv(location gl.UniformLocation, count gl.Sizei, transpose gl.Boolean, value *gl.Float) {
+	r.record("UniformMatrix4fv", location, count, transpose, floats(value, 16*int(count)))
+}
+
+// DrawBuffers records a copy of the buffer list rather than the pointer.
+func (r *RecordingBackend) DrawBuffers(n gl.Sizei, buffers *gl.Enum) {
+	var copied []gl.Enum
+	if buffers != nil && n > 0 {
+		copied = append(copied, unsafe.Slice(buffers, int(n))...)
+	}
+	r.record("DrawBuffers", n, copied)
+}
+
+func floats(value *gl.Float, n int) []gl.Float {
+	if value == nil || n <= 0 {
+		return nil
+	}
+	return append([]gl.Float{}, unsafe.Slice(value, n)...)
+}
+
+func (r *RecordingBackend) ReadPixels(x, y gl.Int, width, height gl.Sizei, format, xtype gl.Enum, pixels gl.Pointer) {
+	r.record("ReadPixels", x, y, width, height, format, xtype, pixels)
+	if r.PixelSource == nil || pixels == nil {
+		return
+	}
+	size := int(width) * int(height) * pixelSize(format, xtype)
+	source := r.PixelSource(int(x), int(y), int(width), int(height), format, xtype)
+	copy(unsafe.Slice((*byte)(pixels), size), source)
+}
+
+func pixelSize(format, xtype gl.Enum) int {
+	if xtype == gl.UNSIGNED_INT_24_8 {
+		return 4
+	}
+	components := 1
+	switch format {
+	case gl.RG, gl.RG_INTEGER:
+		components = 2
+	case gl.RGB, gl.BGR, gl.RGB_INTEGER:
+		components = 3
+	case gl.RGBA, gl.BGRA, gl.RGBA_INTEGER:
+		components = 4
+	}
+	return components * ComponentSize(xtype)
+}
+
+func boolInt(b bool) gl.Int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (r *RecordingBackend) ActiveTexture(texture gl.Enum) {
+	r.record("ActiveTexture", texture)
+}
+
+func (r *RecordingBackend) AttachShader(program gl.Program, shader gl.Uint) {
+	r.record("AttachShader", program, shader)
+}
+
+func (r *RecordingBackend) BindBufferBase(target gl.Enum, index gl.Uint, buffer gl.Buffer) {
+	r.record("BindBufferBase", target, index, buffer)
+}
+
+func (r *RecordingBackend) BindRenderbuffer(target gl.Enum, renderbuffer gl.Renderbuffer) {
+	r.record("BindRenderbuffer", target, renderbuffer)
+}
+
+func (r *RecordingBackend) BindVertexArray(vao gl.VertexArrayObject) {
+	r.record("BindVertexArray", vao)
+}
+
+func (r *RecordingBackend) BlitFramebuffer(srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1 gl.Int, mask gl.Bitfield, filter gl.Enum) {
+	r.record("BlitFramebuffer", srcX0, srcY0, srcX1, srcY1, dstX0, dstY0, dstX1, dstY1, mask, filter)
+}
+
+func (r *RecordingBackend) ColorMask(red, green, blue, alpha gl.Boolean) {
+	r.record("ColorMask", red, green, blue, alpha)
+}
+
+func (r *RecordingBackend) CompileShader(shader gl.Uint) {
+	r.record("CompileShader", shader)
+}
+
+func (r *RecordingBackend) CompressedTexImage2D(target gl.Enum, level gl.Int, internalFormat gl.Enum, width, height gl.Sizei, border gl.Int, imageSize gl.Sizei, data gl.Pointer) {
+	r.record("CompressedTexImage2D", target, level, internalFormat, width, height, border, imageSize, data)
+}
+
+func (r *RecordingBackend) CompressedTexImage3D(target gl.Enum, level gl.Int, internalFormat gl.Enum, width, height, depth gl.Sizei, border gl.Int, imageSize gl.Sizei, data gl.Pointer) {
+	r.record("CompressedTexImage3D", target, level, internalFormat, width, height, depth, border, imageSize, data)
+}
+
+func (r *RecordingBackend) DepthFunc(function gl.Enum) {
+	r.record("DepthFunc", function)
+}
+
+func (r *RecordingBackend) DepthMask(flag gl.Boolean) {
+	r.record("DepthMask", flag)
+}
+
+func (r *RecordingBackend) DetachShader(program gl.Program, shader gl.Uint) {
+	r.record("DetachShader", program, shader)
+}
+
+func (r *RecordingBackend) DisableVertexAttribArray(location gl.AttributeLocation) {
+	r.record("DisableVertexAttribArray", location)
+}
+
+func (r *RecordingBackend) DrawBuffer(mode gl.Enum) {
+	r.record("DrawBuffer", mode)
+}
+
+func (r *RecordingBackend) DrawElements(mode gl.Enum, count gl.Sizei, indexType gl.Enum, indices gl.Pointer) {
+	r.record("DrawElements", mode, count, indexType, indices)
+}
+
+func (r *RecordingBackend) EnableVertexAttribArray(location gl.AttributeLocation) {
+	r.record("EnableVertexAttribArray", location)
+}
+
+func (r *RecordingBackend) FramebufferRenderbuffer(target, attachment, renderbuffertarget gl.Enum, renderbuffer gl.Renderbuffer) {
+	r.record("FramebufferRenderbuffer", target, attachment, renderbuffertarget, renderbuffer)
+}
+
+func (r *RecordingBackend) FramebufferTexture2D(target, attachment, textarget gl.Enum, texture gl.Texture, level gl.Int) {
+	r.record("FramebufferTexture2D", target, attachment, textarget, texture, level)
+}
+
+func (r *RecordingBackend) GenerateMipmap(target gl.Enum) {
+	r.record("GenerateMipmap", target)
+}
+
+func (r *RecordingBackend) LinkProgram(program gl.Program) {
+	r.record("LinkProgram", program)
+}
+
+func (r *RecordingBackend) PixelStorei(pname gl.Enum, param gl.Int) {
+	r.record("PixelStorei", pname, param)
+}
+
+func (r *RecordingBackend) ReadBuffer(mode gl.Enum) {
+	r.record("ReadBuffer", mode)
+}
+
+func (r *RecordingBackend) RenderbufferStorage(target, internalFormat gl.Enum, width, height gl.Sizei) {
+	r.record("RenderbufferStorage", target, internalFormat, width, height)
+}
+
+func (r *RecordingBackend) RenderbufferStorageMultisample(target gl.Enum, samples gl.Sizei, internalFormat gl.Enum, width, height gl.Sizei) {
+	r.record("RenderbufferStorageMultisample", target, samples, internalFormat, width, height)
+}
+
+func (r *RecordingBackend) StencilFunc(function gl.Enum, ref gl.Int, mask gl.Uint) {
+	r.record("StencilFunc", function, ref, mask)
+}
+
+func (r *RecordingBackend) StencilOp(sfail, dpfail, dppass gl.Enum) {
+	r.record("StencilOp", sfail, dpfail, dppass)
+}
+
+func (r *RecordingBackend) TexImage2D(target gl.Enum, level gl.Int, internalFormat gl.Int, width, height gl.Sizei, border gl.Int, format, xtype gl.Enum, pixels gl.Pointer) {
+	r.record("TexImage2D", target, level, internalFormat, width, height, border, format, xtype, pixels)
+}
+
+func (r *RecordingBackend) TexImage2DMultisample(target gl.Enum, samples gl.Sizei, internalFormat gl.Int, width, height gl.Sizei, fixedSampleLocations gl.Boolean) {
+	r.record("TexImage2DMultisample", target, samples, internalFormat, width, height, fixedSampleLocations)
+}
+
+func (r *RecordingBackend) TexImage3D(target gl.Enum, level gl.Int, internalFormat gl.Int, width, height, depth gl.Sizei, border gl.Int, format, xtype gl.Enum, pixels gl.Pointer) {
+	r.record("TexImage3D", target, level, internalFormat, width, height, depth, border, format, xtype, pixels)
+}
+
+func (r *RecordingBackend) TexParameterf(target, pname gl.Enum, param gl.Float) {
+	r.record("TexParameterf", target, pname, param)
+}
+
+func (r *RecordingBackend) TexParameteri(target, pname gl.Enum, param gl.Int) {
+	r.record("TexParameteri", target, pname, param)
+}
+
+func (r *RecordingBackend) TexSubImage3D(target gl.Enum, level, xoffset, yoffset, zoffset gl.Int, width, height, depth gl.Sizei, format, xtype gl.Enum, pixels gl.Pointer) {
+	r.record("TexSubImage3D", target, level, xoffset, yoffset, zoffset, width, height, depth, format, xtype, pixels)
+}
+
+func (r *RecordingBackend) Uniform1f(location gl.UniformLocation, v0 gl.Float) {
+	r.record("Uniform1f", location, v0)
+}
+
+func (r *RecordingBackend) Uniform1i(location gl.UniformLocation, v0 gl.Int) {
+	r.record("Uniform1i", location, v0)
+}
+
+func (r *RecordingBackend) Uniform1ui(location gl.UniformLocation, v0 gl.Uint) {
+	r.record("Uniform1ui", location, v0)
+}
+
+func (r *RecordingBackend) Uniform2f(location gl.UniformLocation, v0, v1 gl.Float) {
+	r.record("Uniform2f", location, v0, v1)
+}
+
+func (r *RecordingBackend) Uniform2ui(location gl.UniformLocation, v0, v1 gl.Uint) {
+	r.record("Uniform2ui", location, v0, v1)
+}
+
+func (r *RecordingBackend) Uniform3f(location gl.UniformLocation, v0, v1, v2 gl.Float) {
+	r.record("Uniform3f", location, v0, v1, v2)
+}
+
+func (r *RecordingBackend) Uniform3ui(location gl.UniformLocation, v0, v1, v2 gl.Uint) {
+	r.record("Uniform3ui", location, v0, v1, v2)
+}
+
+func (r *RecordingBackend) Uniform4f(location gl.UniformLocation, v0, v1, v2, v3 gl.Float) {
+	r.record("Uniform4f", location, v0, v1, v2, v3)
+}
+
+func (r *RecordingBackend) Uniform4ui(location gl.UniformLocation, v0, v1, v2, v3 gl.Uint) {
+	r.record("Uniform4ui", location, v0, v1, v2, v3)
+}
+
+func (r *RecordingBackend) UseProgram(program gl.Program) {
+	r.record("UseProgram", program)
+}
+
+func (r *RecordingBackend) VertexAttribIPointer(location gl.AttributeLocation, size gl.Int, xtype gl.Enum, stride gl.Sizei, pointer gl.Pointer) {
+	r.record("VertexAttribIPointer", location, size, xtype, stride, pointer)
+}
+
+func (r *RecordingBackend) VertexAttribPointer(location gl.AttributeLocation, size gl.Int, xtype gl.Enum, normalized gl.Boolean, stride gl.Sizei, pointer gl.Pointer) {
+	r.record("VertexAttribPointer", location, size, xtype, normalized, stride, pointer)
+}
+
+func (r *RecordingBackend) Viewport(x, y gl.Int, width, height gl.Sizei) {
+	r.record("Viewport", x, y, width, height)
+}
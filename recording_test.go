@@ -0,0 +1,77 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestRecordingCopiesMatrices(t *testing.T) {
+	r := NewRecordingBackend()
+	m := make([]gl.Float, 32)
+	for i := range m {
+		m[i] = gl.Float(i)
+	}
+	r.UniformMatrix4fv(1, 2, gl.FALSE, &m[0])
+	want := append([]gl.Float{}, m...)
+	m[0] = 100
+	if got := r.Calls[0].Args[3]; !reflect.DeepEqual(got, want) {
+		t.Errorf("recorded %v, want %v", got, want)
+	}
+}
+
+func TestRecordingReadPixels(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.PixelSource = func(x, y, width, height int, format, xtype gl.Enum) []byte {
+		if format != gl.RGBA || xtype != gl.UNSIGNED_BYTE {
+			t.Errorf("read format %v type %v", format, xtype)
+		}
+		// gl rows run bottom up.
+		return []byte{
+			1, 2, 3, 4, 5, 6, 7, 8,
+			9, 10, 11, 12, 13, 14, 15, 16,
+		}
+	}
+	img := ReadPixels(0, 0, 2, 2)
+	tests := []struct {
+		x, y  int
+		color color.RGBA
+	}{
+		{0, 0, color.RGBA{9, 10, 11, 12}},
+		{1, 0, color.RGBA{13, 14, 15, 16}},
+		{0, 1, color.RGBA{1, 2, 3, 4}},
+		{1, 1, color.RGBA{5, 6, 7, 8}},
+	}
+	for _, test := range tests {
+		if c := img.RGBAAt(test.x, test.y); c != test.color {
+			t.Errorf("pixel %d,%d is %v, want %v", test.x, test.y, c, test.color)
+		}
+	}
+}
+
+func TestRecordingCompileStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status bool
+		log    string
+		ok     bool
+	}{
+		{"clean", true, "", true},
+		{"warnings", true, "0:1(1): warning: unused variable\n", true},
+		{"errors", false, "0:1(1): error: syntax error\n", false},
+		{"silent failure", false, "", false},
+	}
+	files := memoryFiles(map[string]string{"main.frag": "void main() {}\n"})
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		previous := SetBackend(r)
+		r.CompileStatus, r.CompileLog = test.status, test.log
+		err := LoadShaderWith(files, CreateShader(gl.FRAGMENT_SHADER), "main.frag", ShaderOptions{})
+		SetBackend(previous)
+		if (err == nil) != test.ok {
+			t.Errorf("%s: error %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+type ResourceKind int
+
+const (
+	BufferResource ResourceKind = iota
+	TextureResource
+	VertexArrayResource
+	ShaderResource
+	ProgramResource
+	FramebufferResource
+	RenderbufferResource
+)
+
+func (kind ResourceKind) String() string {
+	switch kind {
+	case BufferResource:
+		return "buffer"
+	case TextureResource:
+		return "texture"
+	case VertexArrayResource:
+		return "vertex array"
+	case ShaderResource:
+		return "shader"
+	case ProgramResource:
+		return "program"
+	case FramebufferResource:
+		return "framebuffer"
+	case RenderbufferResource:
+		return "renderbuffer"
+	}
+	return fmt.Sprintf("ResourceKind(%d)", int(kind))
+}
+
+type Resource struct {
+	Kind ResourceKind
+	Id   gl.Uint
+}
+
+type ResourceTracker struct {
+	mutex sync.Mutex
+	live  map[Resource]string
+}
+
+var Resources = NewResourceTracker()
+
+func NewResourceTracker() *ResourceTracker {
+	return &ResourceTracker{live: make(map[Resource]string)}
+}
+
+func (t *ResourceTracker) Track(kind ResourceKind, id gl.Uint) {
+	if id == 0 {
+		return
+	}
+	site := "unknown"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		site = fmt.Sprintf("%s:%d", file, line)
+	}
+	t.mutex.Lock()
+	t.live[Resource{kind, id}] = site
+	t.mutex.Unlock()
+}
+
+func (t *ResourceTracker) Untrack(kind ResourceKind, id gl.Uint) {
+	t.mutex.Lock()
+	delete(t.live, Resource{kind, id})
+	t.mutex.Unlock()
+}
+
+func (t *ResourceTracker) Live() map[Resource]string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	live := make(map[Resource]string, len(t.live))
+	for r, site := range t.live {
+		live[r] = site
+	}
+	return live
+}
+
+// Report writes every object which was created but never deleted and returns
+// how many there were. Call it at shutdown after releasing everything.
+func (t *ResourceTracker) Report(w io.Writer) int {
+	live := t.Live()
+	lines := make([]string, 0, len(live))
+	for r, site := range live {
+		lines = append(lines, fmt.Sprintf("leaked %s %d created at %s", r.Kind, r.Id, site))
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	return len(lines)
+}
+
+func GenBuffer() gl.Buffer {
+	buffer := Backend.GenBuffer()
+	Resources.Track(BufferResource, gl.Uint(buffer))
+	return buffer
+}
+
+func DeleteBuffer(buffer gl.Buffer) {
+	if buffer != 0 {
+		Backend.DeleteBuffer(buffer)
+		Resources.Untrack(BufferResource, gl.Uint(buffer))
+	}
+}
+
+func GenTexture() gl.Texture {
+	texture := Backend.GenTexture()
+	Resources.Track(TextureResource, gl.Uint(texture))
+	return texture
+}
+
+func DeleteTexture(texture gl.Texture) {
+	if texture != 0 {
+		Backend.DeleteTexture(texture)
+		Resources.Untrack(TextureResource, gl.Uint(texture))
+	}
+}
+
+func GenVertexArray() gl.VertexArrayObject {
+	vao := Backend.GenVertexArray()
+	Resources.Track(VertexArrayResource, gl.Uint(vao))
+	return vao
+}
+
+func DeleteVertexArray(vao gl.VertexArrayObject) {
+	if vao != 0 {
+		Backend.DeleteVertexArray(vao)
+		Resources.Untrack(VertexArrayResource, gl.Uint(vao))
+	}
+}
+
+func CreateShader(shaderType gl.Enum) gl.Uint {
+	shader := Backend.CreateShader(shaderType)
+	Resources.Track(ShaderResource, shader)
+	return shader
+}
+
+func DeleteShader(shader gl.Uint) {
+	if shader != 0 {
+		Backend.DeleteShader(shader)
+		Resources.Untrack(ShaderResource, shader)
+	}
+}
+
+func CreateProgram() gl.Program {
+	program := Backend.CreateProgram()
+	Resources.Track(ProgramResource, gl.Uint(program))
+	return program
+}
+
+func DeleteProgram(program gl.Program) {
+	if program != 0 {
+		Backend.DeleteProgram(program)
+		Resources.Untrack(ProgramResource, gl.Uint(program))
+	}
+}
+
+func GenFramebuffer() gl.Framebuffer {
+	framebuffer := Backend.GenFramebuffer()
+	Resources.Track(FramebufferResource, gl.Uint(framebuffer))
+	return framebuffer
+}
+
+func DeleteFramebuffer(framebuffer gl.Framebuffer) {
+	if framebuffer != 0 {
+		Backend.DeleteFramebuffer(framebuffer)
+		Resources.Untrack(FramebufferResource, gl.Uint(framebuffer))
+	}
+}
+
+func GenRenderbuffer() gl.Renderbuffer {
+	renderbuffer := Backend.GenRenderbuffer()
+	Resources.Track(RenderbufferResource, gl.Uint(renderbuffer))
+	return renderbuffer
+}
+
+func DeleteRenderbuffer(renderbuffer gl.Renderbuffer) {
+	if renderbuffer != 0 {
+		Backend.DeleteRenderbuffer(renderbuffer)
+		Resources.Untrack(RenderbufferResource, gl.Uint(renderbuffer))
+	}
+}
+
+func (elements *DrawElements) Delete() {
+	DeleteBuffer(elements.Buffer)
+	elements.Buffer = 0
+	elements.Count = 0
+}
+
+func (geometry *Geometry) Delete() {
+	deleted := map[gl.Buffer]bool{0: true}
+	deleteOnce := func(buffer gl.Buffer) {
+		if !deleted[buffer] {
+			DeleteBuffer(buffer)
+			deleted[buffer] = true
+		}
+	}
+	deleteOnce(geometry.VertexBuffer)
+	deleteOnce(geometry.NormalBuffer)
+	for _, stream := range geometry.Streams {
+		deleteOnce(stream.Buffer)
+	}
+	for _, elements := range geometry.Elements {
+		if elements != nil {
+			elements.Delete()
+		}
+	}
+	geometry.VertexBuffer = 0
+	geometry.NormalBuffer = 0
+	geometry.Streams = nil
+	geometry.Elements = nil
+}
+
+// Delete releases the geometry of the model and its children. Shared
+// geometry is released, and other geometry instanced more than once in the
+// tree is deleted once.
+func (model *Model) Delete() {
+	model.deleteGeometry(map[*Geometry]bool{})
+}
+
+func (model *Model) deleteGeometry(deleted map[*Geometry]bool) {
+	for _, shared := range model.Shared {
+		deleted[shared.Geometry] = true
+		shared.Release()
+	}
+	model.Shared = nil
+	for _, geometry := range model.Geometry {
+		if !deleted[geometry] {
+			geometry.Delete()
+			deleted[geometry] = true
+		}
+	}
+	model.Geometry = nil
+	for _, child := range model.Children {
+		child.deleteGeometry(deleted)
+	}
+}
+
+func (lib *ShaderLibrary) Delete() {
+	for tag, program := range lib.Programs {
+		DeleteProgram(program)
+		delete(lib.Programs, tag)
+	}
+	for tag, shader := range lib.VertexShaders {
+		DeleteShader(gl.Uint(shader))
+		delete(lib.VertexShaders, tag)
+	}
+	for tag, shader := range lib.FragmentShaders {
+		DeleteShader(gl.Uint(shader))
+		delete(lib.FragmentShaders, tag)
+	}
+	for key, shader := range lib.Shaders {
+		DeleteShader(shader)
+		delete(lib.Shaders, key)
+	}
+}
+
+func (lib *ShaderLibrary) DeleteProgram(tag string) {
+	program, ok := lib.Programs[tag]
+	if ok {
+		DeleteProgram(program)
+		delete(lib.Programs, tag)
+	}
+}
+
+func (b *Buffer) Delete() {
+	DeleteBuffer(b.Id)
+	b.Id = 0
+	b.Size = 0
+}
+
+func (ubo *UniformBuffer) Delete() {
+	DeleteBuffer(ubo.Buffer)
+	ubo.Buffer = 0
+}
+
+func (r *RingBuffer) Delete() {
+	if r.mapped {
+		r.Unmap()
+	}
+	for i, fence := range r.fences {
+		if fence != nil {
+			Backend.DeleteSync(fence)
+			r.fences[i] = nil
+		}
+	}
+	r.Buffer.Delete()
+}
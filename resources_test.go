@@ -0,0 +1,108 @@
+package render
+
+import (
+	"bytes"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"strings"
+	"testing"
+)
+
+func TestResourceTracker(t *testing.T) {
+	tracker := NewResourceTracker()
+	// Track records its caller's caller, as it is called from the Gen
+	// functions.
+	track := func(kind ResourceKind, id gl.Uint) { tracker.Track(kind, id) }
+	track(TextureResource, 3)
+	track(BufferResource, 3)
+	track(BufferResource, 0)
+	track(ProgramResource, 1)
+	tracker.Untrack(ProgramResource, 1)
+	tracker.Untrack(ShaderResource, 9)
+	live := tracker.Live()
+	if len(live) != 2 {
+		t.Fatalf("live %v", live)
+	}
+	for resource, site := range live {
+		if !strings.Contains(site, "resources_test.go:") {
+			t.Errorf("%v created at %s", resource, site)
+		}
+	}
+	var report bytes.Buffer
+	if n := tracker.Report(&report); n != 2 {
+		t.Errorf("reported %d leaks", n)
+	}
+	lines := strings.Split(strings.TrimSpace(report.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "leaked buffer 3 created at ") || !strings.HasPrefix(lines[1], "leaked texture 3 created at ") {
+		t.Errorf("report %q", report.String())
+	}
+	tracker.Untrack(BufferResource, 3)
+	tracker.Untrack(TextureResource, 3)
+	report.Reset()
+	if n := tracker.Report(&report); n != 0 || report.Len() != 0 {
+		t.Errorf("reported %d leaks: %q", n, report.String())
+	}
+}
+
+// trackResources replaces the global tracker for the rest of a test.
+func trackResources(t *testing.T) *ResourceTracker {
+	saved := Resources
+	Resources = NewResourceTracker()
+	t.Cleanup(func() { Resources = saved })
+	return Resources
+}
+
+func TestModelDeleteOnce(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	tracker := trackResources(t)
+	instanced := NewGeometry("instanced", []float64{0, 0, 0}, []float64{0, 0, 1}, nil)
+	shared := NewSharedGeometry(NewGeometry("shared", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+	child := &Model{Geometry: []*Geometry{instanced}}
+	child.AddSharedGeometry(shared)
+	model := &Model{Geometry: []*Geometry{instanced}, Children: []*Model{child}}
+	model.AddSharedGeometry(shared)
+	model.Delete()
+	deleted := map[interface{}]bool{}
+	for _, c := range r.Calls {
+		if c.Name != "DeleteBuffer" {
+			continue
+		}
+		if deleted[c.Args[0]] {
+			t.Errorf("buffer %v deleted twice", c.Args[0])
+		}
+		deleted[c.Args[0]] = true
+	}
+	if len(deleted) != 4 || len(r.Live) != 0 || len(tracker.Live()) != 0 {
+		t.Errorf("deleted %v, live %v, tracked %v", deleted, r.Live, tracker.Live())
+	}
+	if shared.Refs() != 0 || model.Shared != nil || child.Geometry != nil {
+		t.Errorf("shared refs %d, model %+v, child %+v", shared.Refs(), model, child)
+	}
+}
+
+func TestShaderLibraryDelete(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	tracker := trackResources(t)
+	lib := NewShaderLibrary()
+	lib.VertexShaders["v"] = gl.VertexShader(CreateShader(gl.VERTEX_SHADER))
+	lib.FragmentShaders["f"] = gl.FragmentShader(CreateShader(gl.FRAGMENT_SHADER))
+	lib.Shaders["s"] = CreateShader(gl.GEOMETRY_SHADER)
+	lib.Programs["p"] = CreateProgram()
+	lib.Programs["q"] = CreateProgram()
+	lib.DeleteProgram("p")
+	lib.DeleteProgram("undefined")
+	if _, ok := lib.GetProgram("p"); ok || r.Count("DeleteProgram") != 1 {
+		t.Errorf("program p not deleted: %v", lib.Programs)
+	}
+	lib.Delete()
+	if len(lib.Programs)+len(lib.VertexShaders)+len(lib.FragmentShaders)+len(lib.Shaders) != 0 {
+		t.Errorf("library not emptied: %+v", lib)
+	}
+	if r.Count("DeleteProgram") != 2 || r.Count("DeleteShader") != 3 {
+		t.Errorf("calls %v", r.Names())
+	}
+	if len(r.Live) != 0 || len(tracker.Live()) != 0 {
+		t.Errorf("live %v, tracked %v", r.Live, tracker.Live())
+	}
+}
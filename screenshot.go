@@ -0,0 +1,172 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+	"image/png"
+	"os"
+)
+
+// ReadPixels reads a rectangle of the bound read framebuffer, flipping the
+// rows so the top of the frame is the first row of the image.
+func ReadPixels(x, y, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if len(img.Pix) == 0 {
+		return img
+	}
+	Backend.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	Backend.ReadPixels(gl.Int(x), gl.Int(y), gl.Sizei(width), gl.Sizei(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Pointer(&img.Pix[0]))
+	flipRGBA(img)
+	return img
+}
+
+func flipRGBA(img *image.RGBA) {
+	height := img.Bounds().Dy()
+	row := make([]byte, img.Stride)
+	for y := 0; y < height/2; y++ {
+		top := img.Pix[y*img.Stride : (y+1)*img.Stride]
+		bottom := img.Pix[(height-1-y)*img.Stride : (height-y)*img.Stride]
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+}
+
+// Screenshot reads the back buffer of the default framebuffer, so it should
+// be taken after drawing and before swapping buffers. The window's alpha
+// channel is rarely meaningful, so the image is made opaque.
+func Screenshot(width, height int) *image.RGBA {
+	Backend.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	Backend.ReadBuffer(gl.BACK)
+	img := ReadPixels(0, 0, width, height)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+	return img
+}
+
+// ReadPixels reads the index-th color attachment. Multisampled framebuffers
+// must be resolved first, and integer attachments cannot be read as bytes.
+func (fb *Framebuffer) ReadPixels(index int) (*image.RGBA, error) {
+	if fb.Samples > 0 {
+		return nil, errors.New("framebuffer: resolve a multisampled framebuffer before reading it")
+	}
+	colors := fb.colorPoints()
+	if index < 0 || index >= len(colors) {
+		return nil, fmt.Errorf("framebuffer %d has no color attachment %d", fb.Id, index)
+	}
+	for i := range fb.Attachments {
+		a := &fb.Attachments[i]
+		if a.Point != colors[index] {
+			continue
+		}
+		if format, _, err := storageFormat(a.InternalFormat); err == nil && integerFormat(format) {
+			return nil, fmt.Errorf("framebuffer %d color attachment %d has integer format 0x%X", fb.Id, index, a.InternalFormat)
+		}
+	}
+	Backend.BindFramebuffer(gl.READ_FRAMEBUFFER, fb.Id)
+	Backend.ReadBuffer(colors[index])
+	img := ReadPixels(0, 0, fb.Width, fb.Height)
+	Backend.ReadBuffer(colors[0])
+	Backend.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	return img, nil
+}
+
+func SavePNG(filename string, img image.Image) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(file, img)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+type recordedFrame struct {
+	Filename string
+	Image    *image.RGBA
+}
+
+// FrameRecorder saves every Every-th frame as a numbered PNG, for turning
+// into a video. Pattern is a fmt format taking the frame number, such as
+// "frames/%05d.png". Frames are read from Source, or the default
+// framebuffer when it is nil, and encoded on a separate goroutine.
+type FrameRecorder struct {
+	Pattern string
+	Every   int
+	Source  *Framebuffer
+	frame   int
+	saved   int
+	frames  chan recordedFrame
+	done    chan struct{}
+	closed  bool
+	err     error
+}
+
+func NewFrameRecorder(pattern string, every int) *FrameRecorder {
+	r := &FrameRecorder{
+		Pattern: pattern,
+		Every:   every,
+		frames:  make(chan recordedFrame, 4),
+		done:    make(chan struct{}),
+	}
+	go r.save()
+	return r
+}
+
+func (r *FrameRecorder) save() {
+	defer close(r.done)
+	for frame := range r.frames {
+		err := SavePNG(frame.Filename, frame.Image)
+		if err != nil && r.err == nil {
+			r.err = err
+		}
+	}
+}
+
+// Frame counts a drawn frame, capturing it if it is due. Call it after
+// drawing and before swapping buffers, such as from a game loop delegate's
+// CaptureFrame. Frames after Close are an error.
+func (r *FrameRecorder) Frame(width, height int) error {
+	if r.closed {
+		return errors.New("screenshot: frame recorder is closed")
+	}
+	every := r.Every
+	if every < 1 {
+		every = 1
+	}
+	due := r.frame%every == 0
+	r.frame++
+	if !due {
+		return nil
+	}
+	var img *image.RGBA
+	if r.Source != nil {
+		var err error
+		img, err = r.Source.ReadPixels(0)
+		if err != nil {
+			return err
+		}
+	} else {
+		img = Screenshot(width, height)
+	}
+	r.frames <- recordedFrame{fmt.Sprintf(r.Pattern, r.saved), img}
+	r.saved++
+	return nil
+}
+
+// Close waits for queued frames to be written and returns the first error
+// met writing them. Closing again returns the same error.
+func (r *FrameRecorder) Close() error {
+	if !r.closed {
+		r.closed = true
+		close(r.frames)
+	}
+	<-r.done
+	return r.err
+}
@@ -0,0 +1,154 @@
+package render
+
+import (
+	"bytes"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rowPixels returns width by height RGBA pixels, bottom row first as GL
+// reads them, whose red channel is the row and alpha is zero.
+func rowPixels(width, height int) []byte {
+	pixels := make([]byte, 0, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixels = append(pixels, byte(y), byte(x), 0, 0)
+		}
+	}
+	return pixels
+}
+
+func TestFlipRGBA(t *testing.T) {
+	for height := 0; height <= 3; height++ {
+		img := image.NewRGBA(image.Rect(0, 0, 2, height))
+		copy(img.Pix, rowPixels(2, height))
+		flipRGBA(img)
+		for y := 0; y < height; y++ {
+			if got := img.Pix[y*img.Stride]; got != byte(height-1-y) {
+				t.Errorf("height %d: row %d holds row %d", height, y, got)
+			}
+		}
+	}
+}
+
+func TestScreenshot(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.PixelSource = func(x, y, width, height int, format, xtype gl.Enum) []byte {
+		return rowPixels(width, height)
+	}
+	img := Screenshot(3, 2)
+	if got := r.Names(); strings.Join(got, " ") != "BindFramebuffer ReadBuffer PixelStorei ReadPixels" {
+		t.Errorf("calls %v", got)
+	}
+	if r.Calls[1].Args[0] != gl.Enum(gl.BACK) {
+		t.Errorf("read buffer %v", r.Calls[1].Args)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			c := img.RGBAAt(x, y)
+			if c.R != byte(1-y) || c.G != byte(x) || c.A != 0xff {
+				t.Errorf("pixel %d,%d is %v", x, y, c)
+			}
+		}
+	}
+}
+
+func TestFramebufferReadPixels(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	fb, err := NewFramebuffer(4, 4, 0, ColorTexture(0, gl.RGBA8), ColorTexture(1, gl.RGBA16F), ColorTexture(2, gl.R32UI), DepthRenderbuffer(gl.DEPTH_COMPONENT24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Reset()
+	if _, err := fb.ReadPixels(1); err != nil {
+		t.Fatal(err)
+	}
+	c0, c1 := gl.Enum(gl.COLOR_ATTACHMENT0), gl.Enum(gl.COLOR_ATTACHMENT0+1)
+	if got := r.Names(); strings.Join(got, " ") != "BindFramebuffer ReadBuffer PixelStorei ReadPixels ReadBuffer BindFramebuffer" {
+		t.Errorf("calls %v", got)
+	}
+	if r.Calls[1].Args[0] != c1 || r.Calls[4].Args[0] != c0 || r.BoundFramebuffers[gl.READ_FRAMEBUFFER] != 0 {
+		t.Errorf("read %v, restored %v, left %d bound", r.Calls[1].Args, r.Calls[4].Args, r.BoundFramebuffers[gl.READ_FRAMEBUFFER])
+	}
+
+	multisampled, err := NewFramebuffer(4, 4, 4, ColorRenderbuffer(0, gl.RGBA8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		fb    *Framebuffer
+		index int
+		err   string
+	}{
+		{"negative", fb, -1, "no color attachment -1"},
+		{"past the end", fb, 3, "no color attachment 3"},
+		{"integer", fb, 2, "integer format"},
+		{"multisampled", multisampled, 0, "resolve"},
+	}
+	for _, test := range tests {
+		r.Reset()
+		img, err := test.fb.ReadPixels(test.index)
+		if img != nil || err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: image %v error %v, want %q", test.name, img != nil, err, test.err)
+		}
+		if r.Count("ReadPixels") != 0 {
+			t.Errorf("%s: read pixels", test.name)
+		}
+	}
+}
+
+func TestFrameRecorder(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.PixelSource = func(x, y, width, height int, format, xtype gl.Enum) []byte {
+		return rowPixels(width, height)
+	}
+	dir := t.TempDir()
+	recorder := NewFrameRecorder(filepath.Join(dir, "%05d.png"), 3)
+	for i := 0; i < 7; i++ {
+		if err := recorder.Frame(2, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := r.Count("ReadPixels"); n != 3 {
+		t.Errorf("captured %d of 7 frames, want 3", n)
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"00000.png", "00001.png", "00002.png"}
+	if len(names) != len(want) {
+		t.Fatalf("saved %v, want %v", names, want)
+	}
+	for i, name := range names {
+		if filepath.Base(name) != want[i] {
+			t.Errorf("saved %s, want %s", filepath.Base(name), want[i])
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil || img.Bounds().Size() != image.Pt(2, 2) {
+			t.Errorf("%s: image %v error %v", name, img, err)
+		}
+	}
+	if err := recorder.Frame(2, 2); err == nil {
+		t.Error("frame after close recorded")
+	}
+	if err := recorder.Close(); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type ShaderError struct {
+	File     string
+	Line     int
+	Column   int
+	Severity string
+	Message  string
+}
+
+func (e ShaderError) String() string {
+	position := e.File
+	if e.Line > 0 {
+		position += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			position += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return fmt.Sprintf("%s: %s: %s", position, e.Severity, e.Message)
+}
+
+type ShaderCompileError struct {
+	Filename string
+	Log      string
+	Errors   []ShaderError
+}
+
+func (e *ShaderCompileError) Error() string {
+	lines := []string{"Failed to compile " + e.Filename}
+	if len(e.Errors) == 0 {
+		lines = append(lines, strings.TrimSpace(e.Log))
+	}
+	for _, err := range e.Errors {
+		lines = append(lines, err.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var shaderLogFormats = []*regexp.Regexp{
+	// Mesa: 0:12(5): error: message
+	regexp.MustCompile(`^(\d+):(\d+)\((\d+)\):\s*(\w+)\s*:\s*(.*)$`),
+	// NVIDIA: 0(12) : error C0000: message
+	regexp.MustCompile(`^(\d+)\((\d+)\)()\s*:\s*(\w+)\s*\w*\s*:\s*(.*)$`),
+	// AMD and Apple: ERROR: 0:12: message
+	regexp.MustCompile(`^(\w+)\s*:\s*(\d+):(\d+):\s*(.*)$`),
+}
+
+func ParseShaderLog(log string, source *ShaderSource) []ShaderError {
+	errors := make([]ShaderError, 0)
+	continued := false
+	for _, raw := range strings.Split(log, "\n") {
+		line := strings.TrimSpace(strings.TrimRight(raw, "\x00"))
+		if line == "" {
+			continue
+		}
+		err, ok := parseShaderLogLine(line)
+		if !ok {
+			// Indented lines continue the previous error until an
+			// unrecognised line interrupts it.
+			continued = continued && strings.TrimLeft(raw, " \t") != raw
+			if continued {
+				errors[len(errors)-1].Message += "\n" + line
+			}
+			continue
+		}
+		continued = true
+		file, _ := strconv.Atoi(err.File)
+		if source != nil {
+			err.File = source.FileName(file)
+		}
+		errors = append(errors, err)
+	}
+	return errors
+}
+
+func parseShaderLogLine(line string) (ShaderError, bool) {
+	for i, format := range shaderLogFormats {
+		match := format.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		var err ShaderError
+		if i == 2 {
+			err.Severity, err.File, err.Message = match[1], match[2], match[4]
+			err.Line, _ = strconv.Atoi(match[3])
+		} else {
+			err.File, err.Severity, err.Message = match[1], match[4], match[5]
+			err.Line, _ = strconv.Atoi(match[2])
+			err.Column, _ = strconv.Atoi(match[3])
+		}
+		err.Severity = strings.ToLower(err.Severity)
+		return err, true
+	}
+	return ShaderError{}, false
+}
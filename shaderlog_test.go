@@ -0,0 +1,76 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShaderLog(t *testing.T) {
+	source := &ShaderSource{Files: []string{"main.frag", "lib/light.glsl"}}
+	tests := []struct {
+		name   string
+		log    string
+		source *ShaderSource
+		errors []ShaderError
+	}{
+		{
+			"nvidia", "0(12) : error C0000: syntax error, unexpected '}'\n0(3) : warning C7533: global variable gl_FragColor is deprecated\n", nil,
+			[]ShaderError{
+				{"0", 12, 0, "error", "syntax error, unexpected '}'"},
+				{"0", 3, 0, "warning", "global variable gl_FragColor is deprecated"},
+			},
+		},
+		{
+			"mesa", "0:12(5): error: `x' undeclared\n1:4(10): warning: unused variable\x00", nil,
+			[]ShaderError{
+				{"0", 12, 5, "error", "`x' undeclared"},
+				{"1", 4, 10, "warning", "unused variable"},
+			},
+		},
+		{
+			"apple", "ERROR: 0:12: Use of undeclared identifier 'x'\nWARNING: 1:7: Overflow in implicit constant conversion\n", nil,
+			[]ShaderError{
+				{"0", 12, 0, "error", "Use of undeclared identifier 'x'"},
+				{"1", 7, 0, "warning", "Overflow in implicit constant conversion"},
+			},
+		},
+		{
+			"continuation", "0:3(1): error: no matching function\n    candidates are: float f(float)\nunrelated\n  ignored\n", nil,
+			[]ShaderError{
+				{"0", 3, 1, "error", "no matching function\ncandidates are: float f(float)"},
+			},
+		},
+		{
+			"file names", "0(2) : error C1008: undefined variable\nERROR: 1:4: bad\n1:9(2): warning: unused\nERROR: 5:1: out of range\n", source,
+			[]ShaderError{
+				{"main.frag", 2, 0, "error", "undefined variable"},
+				{"lib/light.glsl", 4, 0, "error", "bad"},
+				{"lib/light.glsl", 9, 2, "warning", "unused"},
+				{"", 1, 0, "error", "out of range"},
+			},
+		},
+		{
+			"unrecognised", "Fragment shader failed to compile.\n", source,
+			[]ShaderError{},
+		},
+	}
+	for _, test := range tests {
+		errors := ParseShaderLog(test.log, test.source)
+		if !reflect.DeepEqual(errors, test.errors) {
+			t.Errorf("%s: got %q, want %q", test.name, errors, test.errors)
+		}
+	}
+}
+
+func TestShaderCompileError(t *testing.T) {
+	err := &ShaderCompileError{"main.frag", "0:3(1): error: bad\n", []ShaderError{{"lib/light.glsl", 3, 1, "error", "bad"}}}
+	want := "Failed to compile main.frag\nlib/light.glsl:3:1: error: bad"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+	err = &ShaderCompileError{"main.frag", "  driver crashed\n", nil}
+	want = "Failed to compile main.frag\ndriver crashed"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
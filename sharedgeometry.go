@@ -0,0 +1,79 @@
+package render
+
+type SharedGeometry struct {
+	Geometry *Geometry
+	refs     int
+}
+
+func NewSharedGeometry(geometry *Geometry) *SharedGeometry {
+	return &SharedGeometry{geometry, 0}
+}
+
+func (shared *SharedGeometry) Acquire() *SharedGeometry {
+	shared.refs++
+	return shared
+}
+
+// Release drops one reference and deletes the geometry when it was the last,
+// returning whether it was deleted.
+func (shared *SharedGeometry) Release() bool {
+	if shared.refs <= 0 {
+		panic("shared geometry '" + shared.Geometry.Name + "' released too many times")
+	}
+	shared.refs--
+	if shared.refs == 0 {
+		shared.Geometry.Delete()
+		return true
+	}
+	return false
+}
+
+func (shared *SharedGeometry) Refs() int {
+	return shared.refs
+}
+
+func (model *Model) AddSharedGeometry(shared ...*SharedGeometry) *Model {
+	for _, s := range shared {
+		model.Shared = append(model.Shared, s.Acquire())
+		model.Geometry = append(model.Geometry, s.Geometry)
+	}
+	return model
+}
+
+func (model *Model) RemoveSharedGeometry(shared *SharedGeometry) bool {
+	for i, s := range model.Shared {
+		if s == shared {
+			model.Shared = append(model.Shared[:i], model.Shared[i+1:]...)
+			model.removeGeometry(shared.Geometry)
+			shared.Release()
+			return true
+		}
+	}
+	return false
+}
+
+func (model *Model) ReplaceSharedGeometry(old, replacement *SharedGeometry) bool {
+	for i, s := range model.Shared {
+		if s == old {
+			model.Shared[i] = replacement.Acquire()
+			for j, geometry := range model.Geometry {
+				if geometry == old.Geometry {
+					model.Geometry[j] = replacement.Geometry
+					break
+				}
+			}
+			old.Release()
+			return true
+		}
+	}
+	return false
+}
+
+func (model *Model) removeGeometry(geometry *Geometry) {
+	for i, g := range model.Geometry {
+		if g == geometry {
+			model.Geometry = append(model.Geometry[:i], model.Geometry[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"testing"
+)
+
+func TestSharedGeometryRefs(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	shared := NewSharedGeometry(NewGeometry("mesh", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+	replacement := NewSharedGeometry(NewGeometry("replacement", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+	both := len(r.Live)
+	one := both / 2
+	a := (&Model{}).AddSharedGeometry(shared)
+	b := (&Model{}).AddSharedGeometry(shared)
+	steps := []struct {
+		name        string
+		step        func() bool
+		result      bool
+		shared      int
+		replacement int
+		live        int
+	}{
+		{"remove absent", func() bool { return a.RemoveSharedGeometry(replacement) }, false, 2, 0, both},
+		{"replace in a", func() bool { return a.ReplaceSharedGeometry(shared, replacement) }, true, 1, 1, both},
+		{"replace absent", func() bool { return a.ReplaceSharedGeometry(shared, replacement) }, false, 1, 1, both},
+		{"release from b", func() bool { return b.RemoveSharedGeometry(shared) }, true, 0, 1, one},
+		{"acquire replacement", func() bool { replacement.Acquire(); return true }, true, 0, 2, one},
+		{"release replacement", replacement.Release, false, 0, 1, one},
+		{"delete a", func() bool { a.Delete(); return true }, true, 0, 0, 0},
+	}
+	for _, step := range steps {
+		if result := step.step(); result != step.result {
+			t.Errorf("%s: returned %v", step.name, result)
+		}
+		if shared.Refs() != step.shared || replacement.Refs() != step.replacement || len(r.Live) != step.live {
+			t.Errorf("%s: refs %d and %d, %d live, want %d %d %d", step.name, shared.Refs(), replacement.Refs(), len(r.Live), step.shared, step.replacement, step.live)
+		}
+	}
+	if len(a.Geometry) != 0 || len(b.Geometry) != 0 {
+		t.Errorf("geometry left in a %v and b %v", a.Geometry, b.Geometry)
+	}
+}
+
+func TestSharedGeometryReplaceKeepsOrder(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	first := NewSharedGeometry(NewGeometry("first", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+	second := NewSharedGeometry(NewGeometry("second", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+	replacement := NewSharedGeometry(NewGeometry("replacement", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+	model := (&Model{}).AddSharedGeometry(first, second)
+	model.ReplaceSharedGeometry(first, replacement)
+	if model.Geometry[0] != replacement.Geometry || model.Geometry[1] != second.Geometry || model.Shared[0] != replacement {
+		t.Errorf("geometry %v shared %v", model.Geometry, model.Shared)
+	}
+	if first.Geometry.VertexBuffer != 0 {
+		t.Error("replaced geometry not deleted")
+	}
+}
+
+func TestSharedGeometryOverRelease(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	shared := NewSharedGeometry(NewGeometry("mesh", []float64{0, 0, 0}, []float64{0, 0, 1}, nil))
+	shared.Acquire()
+	if !shared.Release() || len(r.Live) != 0 {
+		t.Fatalf("last release left %v", r.Live)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("released geometry released again")
+		}
+	}()
+	shared.Release()
+}
@@ -0,0 +1,240 @@
+package render
+
+import (
+	"bytes"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+)
+
+// EXT_texture_filter_anisotropic is not part of gl32.
+const (
+	TEXTURE_MAX_ANISOTROPY_EXT     = 0x84FE
+	MAX_TEXTURE_MAX_ANISOTROPY_EXT = 0x84FF
+)
+
+// TextureOptions control how textures are uploaded. The zero value gives
+// linear filtering, clamped edges, no mipmaps and the image's own format.
+type TextureOptions struct {
+	MinFilter  gl.Enum
+	MagFilter  gl.Enum
+	WrapS      gl.Enum
+	WrapT      gl.Enum
+	WrapR      gl.Enum
+	Mipmaps    bool
+	Anisotropy float32
+	SRGB       bool
+	DropAlpha  bool
+	FlipY      bool
+}
+
+var DefaultTextureOptions = TextureOptions{}
+
+func (o TextureOptions) minFilter() gl.Enum {
+	if o.MinFilter != 0 {
+		return o.MinFilter
+	}
+	if o.Mipmaps {
+		return gl.LINEAR_MIPMAP_LINEAR
+	}
+	return gl.LINEAR
+}
+
+func orDefault(value, def gl.Enum) gl.Enum {
+	if value != 0 {
+		return value
+	}
+	return def
+}
+
+func (o TextureOptions) SetParameters(target gl.Enum) {
+	Backend.TexParameteri(target, gl.TEXTURE_MIN_FILTER, gl.Int(o.minFilter()))
+	Backend.TexParameteri(target, gl.TEXTURE_MAG_FILTER, gl.Int(orDefault(o.MagFilter, gl.LINEAR)))
+	Backend.TexParameteri(target, gl.TEXTURE_WRAP_S, gl.Int(orDefault(o.WrapS, gl.CLAMP_TO_EDGE)))
+	Backend.TexParameteri(target, gl.TEXTURE_WRAP_T, gl.Int(orDefault(o.WrapT, gl.CLAMP_TO_EDGE)))
+	if target != gl.TEXTURE_2D {
+		Backend.TexParameteri(target, gl.TEXTURE_WRAP_R, gl.Int(orDefault(o.WrapR, gl.CLAMP_TO_EDGE)))
+	}
+	if o.Anisotropy > 1 {
+		anisotropy := o.Anisotropy
+		if max := MaxAnisotropy(); anisotropy > max {
+			anisotropy = max
+		}
+		if anisotropy > 1 {
+			Backend.TexParameterf(target, TEXTURE_MAX_ANISOTROPY_EXT, gl.Float(anisotropy))
+		}
+	}
+}
+
+func (o TextureOptions) Pixels(img image.Image) *PixelData {
+	return o.adjust(ImagePixels(img))
+}
+
+func (o TextureOptions) adjust(p *PixelData) *PixelData {
+	if o.FlipY {
+		p.FlipRows()
+	}
+	if o.DropAlpha {
+		switch p.InternalFormat {
+		case gl.RGBA8:
+			p.InternalFormat = gl.RGB8
+		case gl.RGBA16:
+			p.InternalFormat = gl.RGB16
+		}
+	}
+	if o.SRGB {
+		switch p.InternalFormat {
+		case gl.RGB8:
+			p.InternalFormat = gl.SRGB8
+		case gl.RGBA8:
+			p.InternalFormat = gl.SRGB8_ALPHA8
+		}
+	}
+	return p
+}
+
+func (o TextureOptions) GenerateMipmaps(target gl.Enum) {
+	if o.Mipmaps {
+		Backend.GenerateMipmap(target)
+	}
+}
+
+func TexImage2D(target gl.Enum, level int, p *PixelData) {
+	Backend.PixelStorei(gl.UNPACK_ALIGNMENT, gl.Int(p.Alignment))
+	Backend.TexImage2D(
+		target, gl.Int(level), /* target, level of detail */
+		gl.Int(p.InternalFormat),                 /* internal format */
+		gl.Sizei(p.Width), gl.Sizei(p.Height), 0, /* width, height, border */
+		p.Format, p.Type, /* external format, type */
+		p.Pointer(), /* pixels */
+	)
+}
+
+func DecodeImage(filename string) (image.Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	return img, err
+}
+
+func UploadTexture(texture gl.Texture, img image.Image, options TextureOptions) {
+	UploadPixels(texture, options.Pixels(img), options)
+}
+
+// TextureSwizzle reports whether the current context can swizzle texture
+// channels.
+func TextureSwizzle() bool {
+	return VersionAtLeast(3, 3) || HasExtension("GL_ARB_texture_swizzle") || HasExtension("GL_EXT_texture_swizzle")
+}
+
+// swizzled returns pixels the current context samples as intended,
+// expanding them when it cannot swizzle.
+func swizzled(p *PixelData) *PixelData {
+	if p.Swizzle == [4]gl.Enum{} || TextureSwizzle() {
+		return p
+	}
+	return p.Expand()
+}
+
+// SetSwizzle applies the pixels' Swizzle to the bound texture.
+func SetSwizzle(target gl.Enum, p *PixelData) {
+	if p.Swizzle == [4]gl.Enum{} {
+		return
+	}
+	for i, pname := range [4]gl.Enum{TEXTURE_SWIZZLE_R, TEXTURE_SWIZZLE_G, TEXTURE_SWIZZLE_B, TEXTURE_SWIZZLE_A} {
+		Backend.TexParameteri(target, pname, gl.Int(p.Swizzle[i]))
+	}
+}
+
+func UploadPixels(texture gl.Texture, p *PixelData, options TextureOptions) {
+	p = swizzled(p)
+	Backend.BindTexture(gl.TEXTURE_2D, texture)
+	options.SetParameters(gl.TEXTURE_2D)
+	SetSwizzle(gl.TEXTURE_2D, p)
+	TexImage2D(gl.TEXTURE_2D, 0, p)
+	options.GenerateMipmaps(gl.TEXTURE_2D)
+}
+
+// TextureData is a texture read into memory and ready for upload, either
+// a parsed KTX or DDS container or a decoded image.
+type TextureData struct {
+	File   *TextureFile
+	Pixels *PixelData
+}
+
+// DecodeTextureData does all of the work of loading a texture except the
+// upload, so it may run away from the gl thread.
+func DecodeTextureData(data []byte, options TextureOptions) (*TextureData, error) {
+	if IsTextureFile(data) {
+		file, err := ParseTextureFile(data)
+		if err != nil {
+			return nil, err
+		}
+		return &TextureData{File: file}, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &TextureData{Pixels: options.Pixels(img)}, nil
+}
+
+func ReadTextureData(filename string, options TextureOptions) (*TextureData, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTextureData(data, options)
+}
+
+func (data *TextureData) Upload(texture gl.Texture, options TextureOptions) error {
+	if data.File != nil {
+		return UploadTextureFile(texture, data.File, options)
+	}
+	UploadPixels(texture, data.Pixels, options)
+	return nil
+}
+
+func LoadTextureFrom(texture gl.Texture, r io.Reader, options TextureOptions) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	textureData, err := DecodeTextureData(data, options)
+	if err != nil {
+		return err
+	}
+	return textureData.Upload(texture, options)
+}
+
+func LoadTextureFS(texture gl.Texture, fsys fs.FS, name string, options TextureOptions) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	textureData, err := DecodeTextureData(data, options)
+	if err != nil {
+		return err
+	}
+	return textureData.Upload(texture, options)
+}
+
+func LoadTexture(texture gl.Texture, filename string) error {
+	return LoadTextureWithOptions(texture, filename, DefaultTextureOptions)
+}
+
+// LoadTextureWithOptions loads a KTX or DDS container directly and decodes
+// any other file as an image.
+func LoadTextureWithOptions(texture gl.Texture, filename string, options TextureOptions) error {
+	data, err := ReadTextureData(filename, options)
+	if err != nil {
+		return err
+	}
+	return data.Upload(texture, options)
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"testing"
+)
+
+func TestSetParametersAnisotropy(t *testing.T) {
+	tests := []struct {
+		name       string
+		extensions []string
+		max        gl.Float
+		requested  float32
+		set        []Call
+	}{
+		{"unsupported", nil, 16, 8, nil},
+		{"clamped", []string{"GL_EXT_texture_filter_anisotropic"}, 4, 8, []Call{
+			call("TexParameterf", gl.Enum(gl.TEXTURE_2D), gl.Enum(TEXTURE_MAX_ANISOTROPY_EXT), gl.Float(4)),
+		}},
+		{"within range", []string{"GL_ARB_texture_filter_anisotropic"}, 16, 8, []Call{
+			call("TexParameterf", gl.Enum(gl.TEXTURE_2D), gl.Enum(TEXTURE_MAX_ANISOTROPY_EXT), gl.Float(8)),
+		}},
+		{"not requested", []string{"GL_EXT_texture_filter_anisotropic"}, 16, 1, nil},
+	}
+	for _, test := range tests {
+		r := NewRecordingBackend()
+		r.Extensions, r.Anisotropy = test.extensions, test.max
+		previous := SetBackend(r)
+		options := TextureOptions{Anisotropy: test.requested}
+		options.SetParameters(gl.TEXTURE_2D)
+		options.SetParameters(gl.TEXTURE_2D)
+		SetBackend(previous)
+		var set []Call
+		for _, c := range r.Calls {
+			if c.Name == "TexParameterf" {
+				set = append(set, c)
+			}
+		}
+		want := append(append([]Call{}, test.set...), test.set...)
+		if test.set == nil {
+			want = nil
+		}
+		checkCalls(t, test.name, set, want)
+		queries := r.Count("GetFloatv")
+		if test.extensions == nil || test.requested <= 1 {
+			if queries != 0 {
+				t.Errorf("%s: queried the maximum without needing it", test.name)
+			}
+		} else if queries != 1 {
+			t.Errorf("%s: queried the maximum %d times, want once", test.name, queries)
+		}
+		reads := len(test.extensions)
+		if test.requested <= 1 {
+			reads = 0
+		}
+		if n := r.Count("GetStringi"); n != reads {
+			t.Errorf("%s: read %d extension strings, want %d", test.name, n, reads)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+)
+
+// layerPixels converts a set of same-sized images into pixel data sharing a
+// single format, falling back to 8 bit RGBA when the images disagree.
+func layerPixels(images []image.Image, options TextureOptions) ([]*PixelData, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("texture: no images given")
+	}
+	size := images[0].Bounds().Size()
+	layers := make([]*PixelData, len(images))
+	uniform := true
+	for i, img := range images {
+		if img.Bounds().Size() != size {
+			return nil, fmt.Errorf("texture: image %d is %v, expected %v", i, img.Bounds().Size(), size)
+		}
+		layers[i] = ImagePixels(img)
+		first := layers[0]
+		if layers[i].InternalFormat != first.InternalFormat || layers[i].Format != first.Format || layers[i].Type != first.Type || layers[i].Swizzle != first.Swizzle {
+			uniform = false
+		}
+	}
+	for i, img := range images {
+		if !uniform {
+			layers[i] = ImagePixels(toNRGBA(img))
+			layers[i].InternalFormat = gl.RGBA8
+			layers[i].Swizzle = [4]gl.Enum{}
+		}
+		options.adjust(layers[i])
+	}
+	return layers, nil
+}
+
+func DecodeImages(filenames []string) ([]image.Image, error) {
+	images := make([]image.Image, len(filenames))
+	for i, filename := range filenames {
+		img, err := DecodeImage(filename)
+		if err != nil {
+			return nil, err
+		}
+		images[i] = img
+	}
+	return images, nil
+}
+
+func UploadTextureArray(texture gl.Texture, images []image.Image, options TextureOptions) error {
+	layers, err := layerPixels(images, options)
+	if err != nil {
+		return err
+	}
+	for i := range layers {
+		layers[i] = swizzled(layers[i])
+	}
+	first := layers[0]
+	Backend.BindTexture(gl.TEXTURE_2D_ARRAY, texture)
+	options.SetParameters(gl.TEXTURE_2D_ARRAY)
+	SetSwizzle(gl.TEXTURE_2D_ARRAY, first)
+	Backend.PixelStorei(gl.UNPACK_ALIGNMENT, gl.Int(first.Alignment))
+	Backend.TexImage3D(
+		gl.TEXTURE_2D_ARRAY, 0, /* target, level of detail */
+		gl.Int(first.InternalFormat),                                            /* internal format */
+		gl.Sizei(first.Width), gl.Sizei(first.Height), gl.Sizei(len(layers)), 0, /* width, height, depth, border */
+		first.Format, first.Type, /* external format, type */
+		nil, /* pixels */
+	)
+	for i, p := range layers {
+		Backend.TexSubImage3D(
+			gl.TEXTURE_2D_ARRAY, 0, /* target, level of detail */
+			0, 0, gl.Int(i), /* x, y, layer */
+			gl.Sizei(p.Width), gl.Sizei(p.Height), 1, /* width, height, depth */
+			p.Format, p.Type, /* external format, type */
+			p.Pointer(), /* pixels */
+		)
+	}
+	options.GenerateMipmaps(gl.TEXTURE_2D_ARRAY)
+	return nil
+}
+
+func LoadTextureArray(texture gl.Texture, filenames []string, options TextureOptions) error {
+	images, err := DecodeImages(filenames)
+	if err != nil {
+		return err
+	}
+	return UploadTextureArray(texture, images, options)
+}
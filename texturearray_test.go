@@ -0,0 +1,55 @@
+package render
+
+import (
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestLayerPixels(t *testing.T) {
+	gray := func(v uint8) *image.Gray {
+		img := image.NewGray(image.Rect(0, 0, 2, 2))
+		for i := range img.Pix {
+			img.Pix[i] = v
+		}
+		return img
+	}
+	translucent := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	translucent.SetNRGBA(0, 0, color.NRGBA{1, 2, 3, 4})
+	tests := []struct {
+		name           string
+		images         []image.Image
+		internalFormat gl.Enum
+		err            string
+	}{
+		{"uniform", []image.Image{gray(1), gray(2)}, gl.R8, ""},
+		{"mixed formats", []image.Image{gray(1), translucent}, gl.RGBA8, ""},
+		{"mixed sizes", []image.Image{gray(1), image.NewGray(image.Rect(0, 0, 2, 3))}, 0, "image 1 is (2,3), expected (2,2)"},
+		{"none", nil, 0, "no images"},
+	}
+	for _, test := range tests {
+		layers, err := layerPixels(test.images, TextureOptions{})
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		for i, layer := range layers {
+			if layer.InternalFormat != test.internalFormat || layer.Width != 2 || layer.Height != 2 {
+				t.Errorf("%s: layer %d is 0x%X %dx%d, want 0x%X", test.name, i, layer.InternalFormat, layer.Width, layer.Height, test.internalFormat)
+			}
+		}
+	}
+	// Gray layers widened to RGBA lose their swizzle and keep their value.
+	layers, _ := layerPixels([]image.Image{gray(9), translucent}, TextureOptions{})
+	if layers[0].Swizzle != ([4]gl.Enum{}) || string(layers[0].Pixels[:4]) != string([]byte{9, 9, 9, 255}) {
+		t.Errorf("widened gray layer %+v", layers[0])
+	}
+}
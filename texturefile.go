@@ -0,0 +1,267 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"io/ioutil"
+)
+
+// Compressed formats from EXT_texture_compression_s3tc, EXT_texture_sRGB,
+// ARB_texture_compression_bptc and ARB_ES3_compatibility, none of which are
+// part of gl32.
+const (
+	COMPRESSED_RGB_S3TC_DXT1_EXT        = 0x83F0
+	COMPRESSED_RGBA_S3TC_DXT1_EXT       = 0x83F1
+	COMPRESSED_RGBA_S3TC_DXT3_EXT       = 0x83F2
+	COMPRESSED_RGBA_S3TC_DXT5_EXT       = 0x83F3
+	COMPRESSED_SRGB_S3TC_DXT1_EXT       = 0x8C4C
+	COMPRESSED_SRGB_ALPHA_S3TC_DXT1_EXT = 0x8C4D
+	COMPRESSED_SRGB_ALPHA_S3TC_DXT3_EXT = 0x8C4E
+	COMPRESSED_SRGB_ALPHA_S3TC_DXT5_EXT = 0x8C4F
+	COMPRESSED_RED_RGTC1                = 0x8DBB
+	COMPRESSED_SIGNED_RED_RGTC1         = 0x8DBC
+	COMPRESSED_RG_RGTC2                 = 0x8DBD
+	COMPRESSED_SIGNED_RG_RGTC2          = 0x8DBE
+	COMPRESSED_RGBA_BPTC_UNORM          = 0x8E8C
+	COMPRESSED_SRGB_ALPHA_BPTC_UNORM    = 0x8E8D
+	COMPRESSED_RGB_BPTC_SIGNED_FLOAT    = 0x8E8E
+	COMPRESSED_RGB_BPTC_UNSIGNED_FLOAT  = 0x8E8F
+	COMPRESSED_RGB8_ETC2                = 0x9274
+	COMPRESSED_SRGB8_ETC2               = 0x9275
+	COMPRESSED_RGBA8_ETC2_EAC           = 0x9278
+	COMPRESSED_SRGB8_ALPHA8_ETC2_EAC    = 0x9279
+)
+
+type textureFormat struct {
+	InternalFormat gl.Enum
+	Format         gl.Enum
+	Type           gl.Enum
+	BlockBytes     int // bytes per 4x4 block, zero when uncompressed
+	PixelBytes     int
+}
+
+func compressedFormat(internalFormat gl.Enum, blockBytes int) textureFormat {
+	return textureFormat{internalFormat, 0, 0, blockBytes, 0}
+}
+
+func (f textureFormat) imageSize(width, height, depth int) int {
+	if f.BlockBytes > 0 {
+		return (width + 3) / 4 * ((height + 3) / 4) * f.BlockBytes * depth
+	}
+	return width * height * depth * f.PixelBytes
+}
+
+var srgbFormats = map[gl.Enum]gl.Enum{
+	gl.RGB8:                       gl.SRGB8,
+	gl.RGBA8:                      gl.SRGB8_ALPHA8,
+	COMPRESSED_RGB_S3TC_DXT1_EXT:  COMPRESSED_SRGB_S3TC_DXT1_EXT,
+	COMPRESSED_RGBA_S3TC_DXT1_EXT: COMPRESSED_SRGB_ALPHA_S3TC_DXT1_EXT,
+	COMPRESSED_RGBA_S3TC_DXT3_EXT: COMPRESSED_SRGB_ALPHA_S3TC_DXT3_EXT,
+	COMPRESSED_RGBA_S3TC_DXT5_EXT: COMPRESSED_SRGB_ALPHA_S3TC_DXT5_EXT,
+	COMPRESSED_RGBA_BPTC_UNORM:    COMPRESSED_SRGB_ALPHA_BPTC_UNORM,
+	COMPRESSED_RGB8_ETC2:          COMPRESSED_SRGB8_ETC2,
+	COMPRESSED_RGBA8_ETC2_EAC:     COMPRESSED_SRGB8_ALPHA8_ETC2_EAC,
+}
+
+// TextureFile is a texture read from a KTX or DDS container. Levels are
+// ordered from the base level down, and each level holds one image per
+// array layer and face, layer major.
+type TextureFile struct {
+	Width          int
+	Height         int
+	Depth          int
+	ArrayLayers    int // zero when not an array
+	Faces          int
+	InternalFormat gl.Enum
+	Format         gl.Enum // zero when compressed
+	Type           gl.Enum
+	Alignment      int
+	Levels         []TextureLevel
+}
+
+type TextureLevel struct {
+	Width  int
+	Height int
+	Depth  int
+	Images [][]byte
+}
+
+func (t *TextureFile) Compressed() bool {
+	return t.Format == 0
+}
+
+func (t *TextureFile) Target() gl.Enum {
+	switch {
+	case t.ArrayLayers > 0:
+		return gl.TEXTURE_2D_ARRAY
+	case t.Faces == 6:
+		return gl.TEXTURE_CUBE_MAP
+	case t.Depth > 1:
+		return gl.TEXTURE_3D
+	}
+	return gl.TEXTURE_2D
+}
+
+func (t *TextureFile) images() int {
+	layers := t.ArrayLayers
+	if layers == 0 {
+		layers = 1
+	}
+	return layers * t.Faces
+}
+
+func levelSize(size, level int) int {
+	size >>= uint(level)
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+func (t *TextureFile) level(level int) TextureLevel {
+	return TextureLevel{
+		levelSize(t.Width, level),
+		levelSize(t.Height, level),
+		levelSize(t.Depth, level),
+		nil,
+	}
+}
+
+// maxTextureSize bounds header dimensions so that image sizes cannot
+// overflow.
+const maxTextureSize = 1 << 16
+
+// checkHeader rejects dimensions and layer, face and level counts that size
+// bytes of data could not hold, before anything is allocated for them.
+// Every image takes at least a byte.
+func (t *TextureFile) checkHeader(levels, size int) error {
+	for _, n := range []int{t.Width, t.Height, t.Depth} {
+		if n < 0 || n > maxTextureSize {
+			return fmt.Errorf("texture: invalid size %dx%dx%d", t.Width, t.Height, t.Depth)
+		}
+	}
+	if t.ArrayLayers < 0 || t.ArrayLayers > size || levels > 32 || t.images()*levels > size {
+		return fmt.Errorf("texture: invalid header, %d levels of %d images cannot fit in %d bytes", levels, t.images(), size)
+	}
+	return nil
+}
+
+func byteSlice(data []byte, offset, size int) ([]byte, error) {
+	if offset < 0 || size < 0 || offset > len(data) || size > len(data)-offset {
+		return nil, fmt.Errorf("texture: data truncated at offset %d", offset)
+	}
+	return data[offset : offset+size], nil
+}
+
+func bytePointer(data []byte) gl.Pointer {
+	if len(data) == 0 {
+		return nil
+	}
+	return gl.Pointer(&data[0])
+}
+
+func IsTextureFile(data []byte) bool {
+	return bytes.HasPrefix(data, ktx1Identifier) || bytes.HasPrefix(data, ktx2Identifier) || bytes.HasPrefix(data, ddsMagic)
+}
+
+func ParseTextureFile(data []byte) (*TextureFile, error) {
+	switch {
+	case bytes.HasPrefix(data, ktx1Identifier):
+		return ParseKTX(data)
+	case bytes.HasPrefix(data, ktx2Identifier):
+		return ParseKTX2(data)
+	case bytes.HasPrefix(data, ddsMagic):
+		return ParseDDS(data)
+	}
+	return nil, fmt.Errorf("texture: unrecognised container")
+}
+
+func ReadTextureFile(filename string) (*TextureFile, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTextureFile(data)
+}
+
+// UploadTextureFile uploads every level, face and layer of a container. The
+// filter, wrap, anisotropy and sRGB options apply; mipmaps are generated
+// only for uncompressed files with a single level, and images are never
+// flipped or converted.
+func UploadTextureFile(texture gl.Texture, file *TextureFile, options TextureOptions) error {
+	if file.Faces == 6 && file.ArrayLayers > 0 {
+		return fmt.Errorf("texture: cube map arrays are not supported")
+	}
+	target := file.Target()
+	internalFormat := file.InternalFormat
+	if srgb, ok := srgbFormats[internalFormat]; ok && options.SRGB {
+		internalFormat = srgb
+	}
+	generate := options.Mipmaps && len(file.Levels) == 1 && !file.Compressed()
+	options.Mipmaps = generate || len(file.Levels) > 1
+	Backend.BindTexture(target, texture)
+	options.SetParameters(target)
+	if !generate {
+		Backend.TexParameteri(target, gl.TEXTURE_MAX_LEVEL, gl.Int(len(file.Levels)-1))
+	}
+	Backend.PixelStorei(gl.UNPACK_ALIGNMENT, gl.Int(file.Alignment))
+	for i, level := range file.Levels {
+		switch target {
+		case gl.TEXTURE_2D:
+			file.texImage2D(target, i, internalFormat, level, level.Images[0])
+		case gl.TEXTURE_CUBE_MAP:
+			for face, data := range level.Images {
+				file.texImage2D(CubeFaces[face], i, internalFormat, level, data)
+			}
+		case gl.TEXTURE_2D_ARRAY:
+			file.texImage3D(target, i, internalFormat, level.Width, level.Height, len(level.Images), bytes.Join(level.Images, nil))
+		default:
+			file.texImage3D(target, i, internalFormat, level.Width, level.Height, level.Depth, level.Images[0])
+		}
+	}
+	if generate {
+		Backend.GenerateMipmap(target)
+	}
+	return nil
+}
+
+func (t *TextureFile) texImage2D(target gl.Enum, level int, internalFormat gl.Enum, l TextureLevel, data []byte) {
+	if t.Compressed() {
+		Backend.CompressedTexImage2D(
+			target, gl.Int(level), internalFormat,
+			gl.Sizei(l.Width), gl.Sizei(l.Height), 0,
+			gl.Sizei(len(data)), bytePointer(data),
+		)
+	} else {
+		Backend.TexImage2D(
+			target, gl.Int(level), gl.Int(internalFormat),
+			gl.Sizei(l.Width), gl.Sizei(l.Height), 0,
+			t.Format, t.Type, bytePointer(data),
+		)
+	}
+}
+
+func (t *TextureFile) texImage3D(target gl.Enum, level int, internalFormat gl.Enum, width, height, depth int, data []byte) {
+	if t.Compressed() {
+		Backend.CompressedTexImage3D(
+			target, gl.Int(level), internalFormat,
+			gl.Sizei(width), gl.Sizei(height), gl.Sizei(depth), 0,
+			gl.Sizei(len(data)), bytePointer(data),
+		)
+	} else {
+		Backend.TexImage3D(
+			target, gl.Int(level), gl.Int(internalFormat),
+			gl.Sizei(width), gl.Sizei(height), gl.Sizei(depth), 0,
+			t.Format, t.Type, bytePointer(data),
+		)
+	}
+}
+
+func LoadTextureFile(texture gl.Texture, filename string, options TextureOptions) error {
+	file, err := ReadTextureFile(filename)
+	if err != nil {
+		return err
+	}
+	return UploadTextureFile(texture, file, options)
+}
@@ -0,0 +1,271 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sequence returns n bytes counting up from start.
+func sequence(start byte, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = start + byte(i)
+	}
+	return b
+}
+
+type ktxHeader struct {
+	Type, TypeSize, Format, InternalFormat, BaseInternalFormat uint32
+	Width, Height, Depth, ArrayLayers, Faces, Levels           uint32
+}
+
+// ktx1File writes a KTX 1.1 file in the given byte order. Each level is the
+// image data following its imageSize, which is written as given.
+func ktx1File(order binary.ByteOrder, h ktxHeader, keyValues []byte, imageSizes []uint32, levels [][]byte) []byte {
+	var b bytes.Buffer
+	b.Write(ktx1Identifier)
+	binary.Write(&b, order, uint32(ktxEndianness))
+	binary.Write(&b, order, h)
+	binary.Write(&b, order, uint32(len(keyValues)))
+	b.Write(keyValues)
+	for i, level := range levels {
+		binary.Write(&b, order, imageSizes[i])
+		b.Write(level)
+	}
+	return b.Bytes()
+}
+
+func checkLevels(t *testing.T, name string, file *TextureFile, want []TextureLevel) {
+	t.Helper()
+	if !reflect.DeepEqual(file.Levels, want) {
+		t.Errorf("%s: levels\n%v\nwant\n%v", name, file.Levels, want)
+	}
+}
+
+func TestParseKTXBigEndian(t *testing.T) {
+	h := ktxHeader{gl.UNSIGNED_SHORT, 2, gl.RGB, gl.RGB16, gl.RGB, 1, 1, 0, 0, 1, 1}
+	pixel := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0, 0}
+	data := ktx1File(binary.BigEndian, h, sequence(0, 8), []uint32{6}, [][]byte{pixel})
+	file, err := ParseKTX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Type != gl.UNSIGNED_SHORT || file.Format != gl.RGB || file.InternalFormat != gl.RGB16 || file.Alignment != 4 {
+		t.Errorf("format %+v", file)
+	}
+	checkLevels(t, "big endian", file, []TextureLevel{
+		{1, 1, 1, [][]byte{{0x02, 0x01, 0x04, 0x03, 0x06, 0x05}}},
+	})
+}
+
+func TestParseKTXCubeFacePadding(t *testing.T) {
+	h := ktxHeader{gl.UNSIGNED_BYTE, 1, gl.RGB, gl.RGB8, gl.RGB, 1, 1, 0, 0, 6, 1}
+	var faces []byte
+	var want [][]byte
+	for i := 0; i < 6; i++ {
+		face := sequence(byte(10*i), 3)
+		want = append(want, face)
+		faces = append(append(faces, face...), 0xff)
+	}
+	// A cube map's imageSize is the size of one face, without padding.
+	file, err := ParseKTX(ktx1File(binary.LittleEndian, h, nil, []uint32{3}, [][]byte{faces}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Target() != gl.TEXTURE_CUBE_MAP {
+		t.Errorf("target %v", file.Target())
+	}
+	checkLevels(t, "cube", file, []TextureLevel{{1, 1, 1, want}})
+}
+
+func TestParseKTXArrayImageSize(t *testing.T) {
+	h := ktxHeader{gl.UNSIGNED_BYTE, 1, gl.RGBA, gl.RGBA8, gl.RGBA, 2, 1, 0, 2, 1, 2}
+	// Outside cube maps imageSize covers every layer of the level.
+	level0, level1 := sequence(0, 16), sequence(100, 8)
+	file, err := ParseKTX(ktx1File(binary.LittleEndian, h, nil, []uint32{16, 8}, [][]byte{level0, level1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Target() != gl.TEXTURE_2D_ARRAY {
+		t.Errorf("target %v", file.Target())
+	}
+	checkLevels(t, "array", file, []TextureLevel{
+		{2, 1, 1, [][]byte{level0[:8], level0[8:]}},
+		{1, 1, 1, [][]byte{level1[:4], level1[4:]}},
+	})
+}
+
+func TestParseKTX2LevelIndex(t *testing.T) {
+	var b bytes.Buffer
+	b.Write(ktx2Identifier)
+	// vkFormat R8G8B8A8_UNORM, 2x2, two layers, two levels.
+	binary.Write(&b, binary.LittleEndian, [9]uint32{37, 1, 2, 2, 0, 2, 1, 2, 0})
+	binary.Write(&b, binary.LittleEndian, [8]uint32{})
+	// KTX2 stores the smallest level first, so the index runs backwards
+	// through the file.
+	level0, level1 := sequence(0, 32), sequence(200, 8)
+	start := 80 + 2*24
+	binary.Write(&b, binary.LittleEndian, [6]uint64{
+		uint64(start + len(level1)), uint64(len(level0)), uint64(len(level0)),
+		uint64(start), uint64(len(level1)), uint64(len(level1)),
+	})
+	b.Write(level1)
+	b.Write(level0)
+	file, err := ParseKTX2(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.InternalFormat != gl.RGBA8 || file.Format != gl.RGBA || file.ArrayLayers != 2 {
+		t.Errorf("format %+v", file)
+	}
+	checkLevels(t, "ktx2", file, []TextureLevel{
+		{2, 2, 1, [][]byte{level0[:16], level0[16:]}},
+		{1, 1, 1, [][]byte{level1[:4], level1[4:]}},
+	})
+}
+
+type ddsOptions struct {
+	width, height, levels uint32
+	caps2                 uint32
+	pixelFlags, bits, red uint32
+	fourCC                string
+	dx10                  []uint32
+}
+
+func ddsFile(o ddsOptions, payload []byte) []byte {
+	header := make([]byte, 128)
+	copy(header, ddsMagic)
+	order := binary.LittleEndian
+	order.PutUint32(header[4:], 124)
+	order.PutUint32(header[12:], o.height)
+	order.PutUint32(header[16:], o.width)
+	order.PutUint32(header[28:], o.levels)
+	order.PutUint32(header[76:], 32)
+	order.PutUint32(header[80:], o.pixelFlags)
+	copy(header[84:], o.fourCC)
+	order.PutUint32(header[88:], o.bits)
+	order.PutUint32(header[92:], o.red)
+	order.PutUint32(header[112:], o.caps2)
+	var b bytes.Buffer
+	b.Write(header)
+	binary.Write(&b, order, o.dx10)
+	b.Write(payload)
+	return b.Bytes()
+}
+
+func TestParseDDSDX10MipOrder(t *testing.T) {
+	// A 2x2 RGBA8 array of two layers with two levels. Each layer holds
+	// its whole mip chain before the next layer starts.
+	layer0, layer1 := sequence(0, 20), sequence(100, 20)
+	data := ddsFile(ddsOptions{
+		width: 2, height: 2, levels: 2,
+		pixelFlags: ddsPixelFormatFourCC, fourCC: "DX10",
+		dx10: []uint32{28, 3, 0, 2, 0},
+	}, append(append([]byte{}, layer0...), layer1...))
+	file, err := ParseDDS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.InternalFormat != gl.RGBA8 || file.Target() != gl.TEXTURE_2D_ARRAY {
+		t.Errorf("format %+v", file)
+	}
+	checkLevels(t, "dx10", file, []TextureLevel{
+		{2, 2, 1, [][]byte{layer0[:16], layer1[:16]}},
+		{1, 1, 1, [][]byte{layer0[16:], layer1[16:]}},
+	})
+}
+
+func TestParseDDSCubeMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ddsOptions
+	}{
+		{"legacy", ddsOptions{width: 1, height: 1, levels: 1, caps2: ddsCubeMap | 0xfc00, pixelFlags: ddsPixelFormatRGB, bits: 32, red: 0xff}},
+		{"dx10", ddsOptions{width: 1, height: 1, levels: 1, pixelFlags: ddsPixelFormatFourCC, fourCC: "DX10", dx10: []uint32{28, 3, dx10CubeMap, 1, 0}}},
+	}
+	payload := sequence(0, 24)
+	var faces [][]byte
+	for i := 0; i < 6; i++ {
+		faces = append(faces, payload[4*i:4*i+4])
+	}
+	for _, test := range tests {
+		file, err := ParseDDS(ddsFile(test.options, payload))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if file.Target() != gl.TEXTURE_CUBE_MAP || file.Format != gl.RGBA {
+			t.Errorf("%s: format %+v", test.name, file)
+		}
+		checkLevels(t, test.name, file, []TextureLevel{{1, 1, 1, faces}})
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	h := ktxHeader{gl.UNSIGNED_BYTE, 1, gl.RGBA, gl.RGBA8, gl.RGBA, 2, 2, 0, 0, 1, 1}
+	ktx := ktx1File(binary.LittleEndian, h, nil, []uint32{16}, [][]byte{sequence(0, 16)})
+	ktx2 := make([]byte, 80)
+	copy(ktx2, ktx2Identifier)
+	binary.LittleEndian.PutUint32(ktx2[12:], 37)
+	binary.LittleEndian.PutUint32(ktx2[36:], 1)
+	dx10 := ddsFile(ddsOptions{width: 1, height: 1, pixelFlags: ddsPixelFormatFourCC, fourCC: "DX10"}, nil)
+	dds := ddsFile(ddsOptions{width: 2, height: 2, pixelFlags: ddsPixelFormatRGB, bits: 32, red: 0xff}, sequence(0, 16))
+	huge := ^uint32(0)
+	ktxLayers := h
+	ktxLayers.ArrayLayers = huge
+	ktxLevels := h
+	ktxLevels.Levels = huge
+	ktxSize := h
+	ktxSize.Width = huge
+	ktxEmpty := h
+	ktxEmpty.ArrayLayers = 2
+	ktx2Layers := append([]byte{}, ktx2...)
+	binary.LittleEndian.PutUint32(ktx2Layers[32:], huge)
+	// Two layers sharing a level of one byte have nothing each.
+	ktx2Empty := append(append([]byte{}, ktx2...), make([]byte, 24)...)
+	binary.LittleEndian.PutUint32(ktx2Empty[32:], 2)
+	binary.LittleEndian.PutUint64(ktx2Empty[80+8:], 1)
+	ktx2Far := append(append([]byte{}, ktx2...), make([]byte, 24)...)
+	binary.LittleEndian.PutUint64(ktx2Far[80:], 1<<62)
+	binary.LittleEndian.PutUint64(ktx2Far[80+8:], 1<<62)
+	ddsOpts := ddsOptions{width: 2, height: 2, pixelFlags: ddsPixelFormatRGB, bits: 32, red: 0xff}
+	ddsLevels, ddsWidth := ddsOpts, ddsOpts
+	ddsLevels.levels, ddsWidth.width = huge, huge
+	dx10Layers := ddsFile(ddsOptions{width: 1, height: 1, pixelFlags: ddsPixelFormatFourCC, fourCC: "DX10", dx10: []uint32{28, 3, 0, huge, 0}}, sequence(0, 16))
+	tests := []struct {
+		name  string
+		parse func([]byte) (*TextureFile, error)
+		data  []byte
+		err   string
+	}{
+		{"ktx array layers", ParseKTX, ktx1File(binary.LittleEndian, ktxLayers, nil, []uint32{16}, [][]byte{sequence(0, 16)}), "invalid"},
+		{"ktx levels", ParseKTX, ktx1File(binary.LittleEndian, ktxLevels, nil, []uint32{16}, [][]byte{sequence(0, 16)}), "invalid"},
+		{"ktx width", ParseKTX, ktx1File(binary.LittleEndian, ktxSize, nil, []uint32{16}, [][]byte{sequence(0, 16)}), "invalid"},
+		{"ktx empty images", ParseKTX, ktx1File(binary.LittleEndian, ktxEmpty, nil, []uint32{1}, [][]byte{sequence(0, 16)}), "invalid"},
+		{"ktx image size past end", ParseKTX, ktx1File(binary.LittleEndian, h, nil, []uint32{huge}, [][]byte{sequence(0, 16)}), "truncated"},
+		{"ktx2 layers", ParseKTX2, ktx2Layers, "invalid"},
+		{"ktx2 empty images", ParseKTX2, ktx2Empty, "invalid"},
+		{"ktx2 offset overflow", ParseKTX2, ktx2Far, "truncated"},
+		{"dds levels", ParseDDS, ddsFile(ddsLevels, sequence(0, 16)), "invalid"},
+		{"dds width", ParseDDS, ddsFile(ddsWidth, sequence(0, 16)), "invalid"},
+		{"dds dx10 layers", ParseDDS, dx10Layers, "invalid"},
+		{"ktx header", ParseKTX, ktx[:63], "header truncated"},
+		{"ktx level", ParseKTX, ktx[:len(ktx)-1], "truncated"},
+		{"ktx image size", ParseKTX, ktx[:66], "truncated"},
+		{"ktx2 header", ParseKTX2, ktx2[:79], "header truncated"},
+		{"ktx2 level index", ParseKTX2, ktx2, "truncated"},
+		{"dds header", ParseDDS, dds[:127], "header truncated"},
+		{"dds dx10 header", ParseDDS, dx10, "truncated"},
+		{"dds level", ParseDDS, dds[:len(dds)-1], "truncated"},
+	}
+	for _, test := range tests {
+		_, err := test.parse(test.data)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"bytes"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"reflect"
+)
+
+type UniformBuffer struct {
+	Buffer  gl.Buffer
+	Binding gl.Uint
+	Layout  BlockLayout
+	Type    reflect.Type
+	Size    int
+	data    []byte
+}
+
+func NewUniformBuffer(binding gl.Uint, value interface{}) *UniformBuffer {
+	t := reflect.Indirect(reflect.ValueOf(value)).Type()
+	size, _ := LayoutOf(Std140, t)
+	ubo := &UniformBuffer{
+		GenBuffer(),
+		binding,
+		Std140,
+		t,
+		size,
+		nil,
+	}
+	Backend.BindBuffer(gl.UNIFORM_BUFFER, ubo.Buffer)
+	Backend.BufferData(gl.UNIFORM_BUFFER, gl.Sizeiptr(size), nil, gl.DYNAMIC_DRAW)
+	Backend.BindBufferBase(gl.UNIFORM_BUFFER, binding, ubo.Buffer)
+	ubo.Update(value)
+	return ubo
+}
+
+func (ubo *UniformBuffer) Update(value interface{}) {
+	data := Pack(ubo.Layout, value)
+	if len(data) != ubo.Size {
+		panic("uniform buffer value does not match block size")
+	}
+	if ubo.data != nil && bytes.Equal(data, ubo.data) {
+		return
+	}
+	ubo.data = data
+	Backend.BindBuffer(gl.UNIFORM_BUFFER, ubo.Buffer)
+	Backend.BufferSubData(gl.UNIFORM_BUFFER, 0, gl.Sizeiptr(len(data)), gl.Pointer(&data[0]))
+	PanicOnError()
+}
+
+func (ubo *UniformBuffer) Bind() {
+	Backend.BindBufferBase(gl.UNIFORM_BUFFER, ubo.Binding, ubo.Buffer)
+}
+
+func BindUniformBlock(program gl.Program, name string, binding gl.Uint) bool {
+	index := Backend.GetUniformBlockIndex(program, name)
+	if index == gl.INVALID_INDEX {
+		return false
+	}
+	Backend.UniformBlockBinding(program, index, binding)
+	return true
+}
+
+func (lib *ShaderLibrary) BindUniformBlock(name string, binding gl.Uint) []string {
+	bound := make([]string, 0)
+	for tag, program := range lib.Programs {
+		if BindUniformBlock(program, name, binding) {
+			bound = append(bound, tag)
+		}
+	}
+	return bound
+}
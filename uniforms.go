@@ -0,0 +1,182 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	glm "github.com/Jragonmiris/mathgl"
+	"reflect"
+)
+
+type Sampler struct {
+	Unit    gl.Enum
+	Target  gl.Enum
+	Texture gl.Texture
+}
+
+type uniformField struct {
+	Index    int
+	Name     string
+	Location gl.UniformLocation
+	Unit     gl.Enum
+}
+
+type UniformSet struct {
+	Program gl.Program
+	Type    reflect.Type
+	fields  []uniformField
+	values  []interface{}
+}
+
+var (
+	mat4Type    = reflect.TypeOf(glm.Mat4d{})
+	mat3Type    = reflect.TypeOf(glm.Mat3d{})
+	vec2Type    = reflect.TypeOf(glm.Vec2d{})
+	vec3Type    = reflect.TypeOf(glm.Vec3d{})
+	vec4Type    = reflect.TypeOf(glm.Vec4d{})
+	colorType   = reflect.TypeOf(Color{})
+	samplerType = reflect.TypeOf(Sampler{})
+	uvec2Type   = reflect.TypeOf([2]uint32{})
+	uvec3Type   = reflect.TypeOf([3]uint32{})
+	uvec4Type   = reflect.TypeOf([4]uint32{})
+)
+
+func NewUniformSet(program gl.Program, values interface{}) *UniformSet {
+	t := reflect.TypeOf(values)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	set := &UniformSet{Program: program, Type: t}
+	unit := gl.Enum(gl.TEXTURE0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("gl")
+		if name == "" {
+			continue
+		}
+		if !isUniformType(field.Type) {
+			panic(fmt.Sprintln("unsupported uniform type:", field.Name, field.Type))
+		}
+		location := Backend.GetUniformLocation(program, name)
+		PanicOnError()
+		uf := uniformField{i, name, location, 0}
+		if field.Type == textureType {
+			uf.Unit = unit
+			unit++
+		}
+		set.fields = append(set.fields, uf)
+	}
+	set.values = make([]interface{}, len(set.fields))
+	return set
+}
+
+func isUniformType(t reflect.Type) bool {
+	switch t {
+	case mat4Type, mat3Type, vec2Type, vec3Type, vec4Type, colorType, samplerType, textureType, uvec2Type, uvec3Type, uvec4Type:
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Int32, reflect.Uint32, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// Set uploads the fields of values which changed since the last call. The
+// program must be in use.
+func (set *UniformSet) Set(values interface{}) {
+	value := reflect.Indirect(reflect.ValueOf(values))
+	if value.Type() != set.Type {
+		panic(fmt.Sprintln("uniform set for", set.Type, "given", value.Type()))
+	}
+	for i, uf := range set.fields {
+		field := value.Field(uf.Index)
+		v := field.Interface()
+		if field.Type() == samplerType {
+			s := v.(Sampler)
+			Backend.ActiveTexture(s.Unit)
+			Backend.BindTexture(s.Target, s.Texture)
+			if set.values[i] == nil || set.values[i].(Sampler).Unit != s.Unit {
+				Backend.Uniform1i(uf.Location, gl.Int(s.Unit-gl.TEXTURE0))
+			}
+		} else if field.Type() == textureType {
+			Backend.ActiveTexture(uf.Unit)
+			Backend.BindTexture(gl.TEXTURE_2D, v.(gl.Texture))
+			if set.values[i] == nil {
+				Backend.Uniform1i(uf.Location, gl.Int(uf.Unit-gl.TEXTURE0))
+			}
+		} else if set.values[i] != v {
+			SetUniform(uf.Location, field)
+		}
+		set.values[i] = v
+	}
+}
+
+func (set *UniformSet) Invalidate() {
+	for i := range set.values {
+		set.values[i] = nil
+	}
+}
+
+func SetUniform(location gl.UniformLocation, value reflect.Value) {
+	switch value.Type() {
+	case mat4Type:
+		Backend.UniformMatrix4fv(location, 1, gl.FALSE, MatArray(value.Interface().(glm.Mat4d)))
+	case mat3Type:
+		Backend.UniformMatrix3fv(location, 1, gl.FALSE, Mat3Array(value.Interface().(glm.Mat3d)))
+	case vec2Type:
+		v := value.Interface().(glm.Vec2d)
+		Backend.Uniform2f(location, gl.Float(v[0]), gl.Float(v[1]))
+	case vec3Type:
+		v := value.Interface().(glm.Vec3d)
+		Backend.Uniform3f(location, gl.Float(v[0]), gl.Float(v[1]), gl.Float(v[2]))
+	case vec4Type:
+		v := value.Interface().(glm.Vec4d)
+		Backend.Uniform4f(location, gl.Float(v[0]), gl.Float(v[1]), gl.Float(v[2]), gl.Float(v[3]))
+	case colorType:
+		c := value.Interface().(Color)
+		Backend.Uniform4f(location, c[0], c[1], c[2], c[3])
+	case uvec2Type:
+		v := value.Interface().([2]uint32)
+		Backend.Uniform2ui(location, gl.Uint(v[0]), gl.Uint(v[1]))
+	case uvec3Type:
+		v := value.Interface().([3]uint32)
+		Backend.Uniform3ui(location, gl.Uint(v[0]), gl.Uint(v[1]), gl.Uint(v[2]))
+	case uvec4Type:
+		v := value.Interface().([4]uint32)
+		Backend.Uniform4ui(location, gl.Uint(v[0]), gl.Uint(v[1]), gl.Uint(v[2]), gl.Uint(v[3]))
+	default:
+		switch value.Kind() {
+		case reflect.Float32, reflect.Float64:
+			Backend.Uniform1f(location, gl.Float(value.Float()))
+		case reflect.Int, reflect.Int32:
+			Backend.Uniform1i(location, gl.Int(value.Int()))
+		case reflect.Uint32:
+			Backend.Uniform1ui(location, gl.Uint(value.Uint()))
+		case reflect.Bool:
+			if value.Bool() {
+				Backend.Uniform1i(location, 1)
+			} else {
+				Backend.Uniform1i(location, 0)
+			}
+		default:
+			panic(fmt.Sprintln("unsupported uniform type:", value.Type()))
+		}
+	}
+}
+
+func Mat3Array(d glm.Mat3d) *gl.Float {
+	n := len(d)
+	f := make([]gl.Float, n)
+	for i := 0; i < n; i++ {
+		f[i] = gl.Float(d[i])
+	}
+	return &f[0]
+}
+
+func (lib *ShaderLibrary) NewUniformSet(tag string, values interface{}) (*UniformSet, bool) {
+	program, ok := lib.GetProgram(tag)
+	if !ok {
+		return nil, false
+	}
+	return NewUniformSet(program, values), true
+}
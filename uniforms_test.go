@@ -0,0 +1,36 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"testing"
+)
+
+type unsignedUniforms struct {
+	Count uint32    `gl:"count"`
+	Mask  [3]uint32 `gl:"mask"`
+	Index int32     `gl:"index"`
+}
+
+func TestUniformSetUnsigned(t *testing.T) {
+	r := NewRecordingBackend()
+	defer SetBackend(SetBackend(r))
+	r.ActiveUniforms = []ProgramVariable{
+		{"count", gl.UNSIGNED_INT, 1, 0},
+		{"mask", gl.UNSIGNED_INT_VEC3, 1, 1},
+		{"index", gl.INT, 1, 2},
+	}
+	set := NewUniformSet(1, unsignedUniforms{})
+	r.Reset()
+	set.Set(unsignedUniforms{7, [3]uint32{1, 2, 3}, -1})
+	set.Set(unsignedUniforms{7, [3]uint32{1, 2, 4}, -1})
+	want := []string{
+		"Uniform1ui(0, 7)",
+		"Uniform3ui(1, 1, 2, 3)",
+		"Uniform1i(2, -1)",
+		"Uniform3ui(1, 1, 2, 4)",
+	}
+	if fmt.Sprint(r.Calls) != fmt.Sprint(want) {
+		t.Errorf("calls %v, want %v", r.Calls, want)
+	}
+}
@@ -0,0 +1,242 @@
+package render
+
+import (
+	"fmt"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	glm "github.com/Jragonmiris/mathgl"
+	"math"
+	"unsafe"
+)
+
+type VertexAttribute struct {
+	Name       string
+	Components int
+	Type       gl.Enum
+	Normalized bool
+	Integer    bool
+	Offset     int
+}
+
+var (
+	PositionAttribute    = VertexAttribute{Name: "position", Components: 3, Type: gl.FLOAT}
+	NormalAttribute      = VertexAttribute{Name: "normal", Components: 3, Type: gl.FLOAT}
+	UVAttribute          = VertexAttribute{Name: "uv", Components: 2, Type: gl.FLOAT}
+	ColorAttribute       = VertexAttribute{Name: "color", Components: 4, Type: gl.UNSIGNED_BYTE, Normalized: true}
+	TangentAttribute     = VertexAttribute{Name: "tangent", Components: 4, Type: gl.FLOAT}
+	BoneIndexAttribute   = VertexAttribute{Name: "boneIndices", Components: 4, Type: gl.UNSIGNED_BYTE, Integer: true}
+	BoneWeightsAttribute = VertexAttribute{Name: "boneWeights", Components: 4, Type: gl.UNSIGNED_BYTE, Normalized: true}
+)
+
+func (a VertexAttribute) Named(name string) VertexAttribute {
+	a.Name = name
+	return a
+}
+
+func (a VertexAttribute) Size() int {
+	return a.Components * ComponentSize(a.Type)
+}
+
+func ComponentSize(t gl.Enum) int {
+	switch t {
+	case gl.BYTE, gl.UNSIGNED_BYTE:
+		return 1
+	case gl.SHORT, gl.UNSIGNED_SHORT, gl.HALF_FLOAT:
+		return 2
+	case gl.INT, gl.UNSIGNED_INT, gl.FLOAT:
+		return 4
+	case gl.DOUBLE:
+		return 8
+	}
+	panic(fmt.Sprintln("unknown component type:", t))
+}
+
+type VertexFormat struct {
+	Attributes []VertexAttribute
+	Stride     int
+}
+
+// NewVertexFormat packs the attributes in order, each aligned to 4 bytes.
+func NewVertexFormat(attributes ...VertexAttribute) *VertexFormat {
+	format := &VertexFormat{make([]VertexAttribute, len(attributes)), 0}
+	offset := 0
+	for i, a := range attributes {
+		a.Offset = offset
+		format.Attributes[i] = a
+		offset = roundUp(offset+a.Size(), 4)
+	}
+	format.Stride = offset
+	return format
+}
+
+func (format *VertexFormat) Attribute(name string) (VertexAttribute, bool) {
+	for _, a := range format.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return VertexAttribute{}, false
+}
+
+func (format *VertexFormat) Interleave(streams map[string][]float64) ([]byte, int, error) {
+	count := -1
+	for _, a := range format.Attributes {
+		stream, ok := streams[a.Name]
+		if !ok {
+			return nil, 0, fmt.Errorf("no data for vertex attribute '%s'", a.Name)
+		}
+		if len(stream)%a.Components != 0 {
+			return nil, 0, fmt.Errorf("vertex attribute '%s' has %d values, not a multiple of %d", a.Name, len(stream), a.Components)
+		}
+		n := len(stream) / a.Components
+		if count >= 0 && n != count {
+			return nil, 0, fmt.Errorf("vertex attribute '%s' has %d vertices, expected %d", a.Name, n, count)
+		}
+		count = n
+	}
+	if count < 0 {
+		count = 0
+	}
+	data := make([]byte, count*format.Stride)
+	for _, a := range format.Attributes {
+		stream := streams[a.Name]
+		size := ComponentSize(a.Type)
+		for v := 0; v < count; v++ {
+			for c := 0; c < a.Components; c++ {
+				offset := v*format.Stride + a.Offset + c*size
+				putComponent(data[offset:], a, stream[v*a.Components+c])
+			}
+		}
+	}
+	return data, count, nil
+}
+
+func putComponent(data []byte, a VertexAttribute, v float64) {
+	scale := func(max float64) float64 {
+		if a.Normalized {
+			return math.Floor(math.Max(-1, math.Min(1, v))*max + 0.5)
+		}
+		return v
+	}
+	uscale := func(max float64) float64 {
+		return math.Max(0, scale(max))
+	}
+	switch a.Type {
+	case gl.FLOAT:
+		nativeOrder.PutUint32(data, math.Float32bits(float32(v)))
+	case gl.DOUBLE:
+		nativeOrder.PutUint64(data, math.Float64bits(v))
+	case gl.BYTE:
+		data[0] = byte(int8(scale(math.MaxInt8)))
+	case gl.UNSIGNED_BYTE:
+		data[0] = uint8(uscale(math.MaxUint8))
+	case gl.SHORT:
+		nativeOrder.PutUint16(data, uint16(int16(scale(math.MaxInt16))))
+	case gl.UNSIGNED_SHORT:
+		nativeOrder.PutUint16(data, uint16(uscale(math.MaxUint16)))
+	case gl.INT:
+		nativeOrder.PutUint32(data, uint32(int32(scale(math.MaxInt32))))
+	case gl.UNSIGNED_INT:
+		nativeOrder.PutUint32(data, uint32(uscale(math.MaxUint32)))
+	default:
+		panic(fmt.Sprintln("can not interleave component type:", a.Type))
+	}
+}
+
+// BufferOffset converts a byte offset into the bound buffer to the pointer
+// argument expected by the gl functions. The result is never dereferenced
+// on the go side, only handed to the driver, which reads it as an offset.
+// checkptr rejects any pointer made from a small integer, so it is switched
+// off here and only here.
+//
+//go:nocheckptr
+func BufferOffset(offset int) gl.Pointer {
+	return gl.Pointer(unsafe.Add(nil, offset))
+}
+
+type VertexStream struct {
+	Buffer gl.Buffer
+	Format *VertexFormat
+}
+
+func (stream *VertexStream) Enable(attributes map[string]gl.AttributeLocation) []gl.AttributeLocation {
+	enabled := make([]gl.AttributeLocation, 0, len(stream.Format.Attributes))
+	Backend.BindBuffer(gl.ARRAY_BUFFER, stream.Buffer)
+	for _, a := range stream.Format.Attributes {
+		location, ok := attributes[a.Name]
+		if !ok {
+			continue
+		}
+		stride := gl.Sizei(stream.Format.Stride)
+		offset := BufferOffset(a.Offset)
+		if a.Integer {
+			Backend.VertexAttribIPointer(location, gl.Int(a.Components), a.Type, stride, offset)
+		} else {
+			normalized := gl.Boolean(gl.FALSE)
+			if a.Normalized {
+				normalized = gl.TRUE
+			}
+			Backend.VertexAttribPointer(location, gl.Int(a.Components), a.Type, normalized, stride, offset)
+		}
+		Backend.EnableVertexAttribArray(location)
+		enabled = append(enabled, location)
+	}
+	return enabled
+}
+
+func NewInterleavedGeometry(name string, format *VertexFormat, streams map[string][]float64, elements []*DrawElements) (*Geometry, error) {
+	data, _, err := format.Interleave(streams)
+	if err != nil {
+		return nil, err
+	}
+	geometry := &Geometry{Name: name, Elements: elements}
+	geometry.AddStream(format, data)
+	return geometry, nil
+}
+
+func (geometry *Geometry) AddStream(format *VertexFormat, data interface{}) *VertexStream {
+	stream := &VertexStream{GenBuffer(), format}
+	// Upload without ArrayPtr's conversions so the bytes match format.
+	ptr, size, _ := ArrayData(data)
+	Backend.BindBuffer(gl.ARRAY_BUFFER, stream.Buffer)
+	Backend.BufferData(gl.ARRAY_BUFFER, size, ptr, gl.STATIC_DRAW)
+	geometry.Streams = append(geometry.Streams, stream)
+	return stream
+}
+
+func AttributeLocations(info *ProgramInfo) map[string]gl.AttributeLocation {
+	locations := make(map[string]gl.AttributeLocation, len(info.Attributes))
+	for name := range info.Attributes {
+		location, ok := info.AttributeLocation(name)
+		if ok {
+			locations[name] = location
+		}
+	}
+	return locations
+}
+
+func DrawGeometryAttributes(geo *Geometry, attributes map[string]gl.AttributeLocation, vao gl.VertexArrayObject) {
+	Backend.BindVertexArray(vao)
+	enabled := make([]gl.AttributeLocation, 0)
+	for _, stream := range geo.Streams {
+		enabled = append(enabled, stream.Enable(attributes)...)
+	}
+	for _, elem := range geo.Elements {
+		Backend.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, elem.Buffer)
+		Backend.DrawElements(elem.DrawType, gl.Sizei(elem.Count), elem.IndexType, nil)
+		PanicOnError()
+	}
+	for _, location := range enabled {
+		Backend.DisableVertexAttribArray(location)
+	}
+}
+
+func DrawModelAttributes(mv glm.Mat4d, model *Model, modelview gl.UniformLocation, attributes map[string]gl.AttributeLocation, vao gl.VertexArrayObject) {
+	mv2 := mv.Mul4(model.Transform)
+	Backend.UniformMatrix4fv(modelview, 1, gl.FALSE, MatArray(mv2))
+	for _, geo := range model.Geometry {
+		DrawGeometryAttributes(geo, attributes, vao)
+	}
+	for _, child := range model.Children {
+		DrawModelAttributes(mv2, child, modelview, attributes, vao)
+	}
+}
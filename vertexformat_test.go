@@ -0,0 +1,101 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	gl "github.com/GlenKelley/go-gl/gl32"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBufferOffset(t *testing.T) {
+	for _, offset := range []int{0, 4, 12, 4096, 1 << 20} {
+		if p := BufferOffset(offset); uintptr(p) != uintptr(offset) {
+			t.Errorf("BufferOffset(%d) = %v", offset, p)
+		}
+	}
+}
+
+// vertexBytes builds little endian vertex data from a mix of values.
+func vertexBytes(values ...interface{}) []byte {
+	var b bytes.Buffer
+	for _, v := range values {
+		binary.Write(&b, nativeOrder, v)
+	}
+	return b.Bytes()
+}
+
+func TestInterleave(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  *VertexFormat
+		streams map[string][]float64
+		stride  int
+		offsets []int
+		want    []byte
+	}{
+		{"float", NewVertexFormat(
+			VertexAttribute{"position", 3, gl.FLOAT, false, false, 0},
+		), map[string][]float64{"position": {1, 2, 3, 4, 5, 6}}, 12, []int{0},
+			vertexBytes(float32(1), float32(2), float32(3), float32(4), float32(5), float32(6))},
+		{"normalized and padded", NewVertexFormat(
+			VertexAttribute{"position", 2, gl.FLOAT, false, false, 0},
+			VertexAttribute{"color", 4, gl.UNSIGNED_BYTE, true, false, 0},
+			VertexAttribute{"weight", 1, gl.SHORT, true, false, 0},
+		), map[string][]float64{
+			"position": {1, 2, 3, 4},
+			"color":    {0, 0.5, 1, 2, -1, 0.25, 0.75, 1},
+			"weight":   {-1, 0.5},
+		}, 16, []int{0, 8, 12}, vertexBytes(
+			float32(1), float32(2), []uint8{0, 128, 255, 255}, int16(-math.MaxInt16), uint16(0),
+			float32(3), float32(4), []uint8{0, 64, 191, 255}, int16(16384), uint16(0),
+		)},
+		{"integer and double", NewVertexFormat(
+			VertexAttribute{"bone", 1, gl.UNSIGNED_SHORT, false, true, 0},
+			VertexAttribute{"weight", 1, gl.DOUBLE, false, false, 0},
+			VertexAttribute{"id", 1, gl.INT, false, true, 0},
+		), map[string][]float64{"bone": {7}, "weight": {0.125}, "id": {-3}}, 16, []int{0, 4, 12},
+			vertexBytes(uint16(7), uint16(0), float64(0.125), int32(-3))},
+	}
+	for _, test := range tests {
+		if test.format.Stride != test.stride {
+			t.Errorf("%s: stride %d, want %d", test.name, test.format.Stride, test.stride)
+		}
+		for i, a := range test.format.Attributes {
+			if a.Offset != test.offsets[i] {
+				t.Errorf("%s: %s at offset %d, want %d", test.name, a.Name, a.Offset, test.offsets[i])
+			}
+		}
+		data, count, err := test.format.Interleave(test.streams)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if count != len(test.want)/test.stride || !bytes.Equal(data, test.want) {
+			t.Errorf("%s: %d vertices\n% x\nwant\n% x", test.name, count, data, test.want)
+		}
+	}
+}
+
+func TestInterleaveErrors(t *testing.T) {
+	format := NewVertexFormat(
+		VertexAttribute{"position", 3, gl.FLOAT, false, false, 0},
+		VertexAttribute{"normal", 3, gl.FLOAT, false, false, 0},
+	)
+	tests := []struct {
+		name    string
+		streams map[string][]float64
+		err     string
+	}{
+		{"missing", map[string][]float64{"position": {0, 0, 0}}, "no data"},
+		{"partial vertex", map[string][]float64{"position": {0, 0}, "normal": {0, 0, 1}}, "not a multiple"},
+		{"mismatched counts", map[string][]float64{"position": {0, 0, 0, 1, 1, 1}, "normal": {0, 0, 1}}, "expected"},
+	}
+	for _, test := range tests {
+		_, _, err := format.Interleave(test.streams)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %v, want %q", test.name, err, test.err)
+		}
+	}
+}